@@ -1,66 +1,176 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
-	_ "github.com/P1coFly/vk_movies/docs"
+	"github.com/P1coFly/vk_movies/docs"
+	"github.com/P1coFly/vk_movies/internal/auth"
 	"github.com/P1coFly/vk_movies/internal/config"
-	"github.com/P1coFly/vk_movies/internal/http-server/handler"
+	"github.com/P1coFly/vk_movies/internal/http-server/middleware"
 	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+	"github.com/P1coFly/vk_movies/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
+const usage = `usage: apiserver [command]
+
+commands:
+  serve                 run the http api server (default)
+  migrate up            apply all pending migrations
+  migrate down [N]      roll back the last N migrations (default 1)
+  migrate status        print applied and pending migrations
+  seed [PATH...]        load fixtures from files or directories (default fixtures/dev)
+  help                  print this help and the supported environment variables
+`
+
 // @title Vk Movies API
 // @version 1.0
 // @description This is a RESTful API service for managing movies and actors
 // @host localhost:8080
-// @BasePath /api
+// @BasePath /
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description Токен администратора из конфигурации или "Bearer <access token>", полученный в /api/login
+// @securityDefinitions.apikey ApiKeyHeader
+// @in header
+// @name X-API-Key
+// @description API-ключ сервисного клиента, выданный администратором в /api/apikeys
 func main() {
-	cfg := config.MustLoad()
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	if command == "help" || command == "-h" || command == "--help" {
+		fmt.Print(usage, "\n", config.Usage())
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	log := setupLogger(cfg.Env)
+	log := setupLogger(cfg.SlogLevel())
+	// Код вне запросов, которому не передали логгер, пишет в тот же лог
+	slog.SetDefault(log)
 
+	switch command {
+	case "serve":
+		err = serve(cfg, log)
+	case "migrate":
+		err = migrate(cfg, log, os.Args[2:])
+	case "seed":
+		err = seedFixtures(cfg, log, os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Error("command failed", "command", command, "error", err)
+		os.Exit(1)
+	}
+}
+
+func serve(cfg *config.Config, log *slog.Logger) error {
 	log.Info("starting api-servies", "env", cfg.Env)
 	log.Debug("cfg data", "data", cfg)
 
-	storage, err := postgresql.New(cfg.Host_db)
+	storage, err := postgresql.New(cfg.StorageDSN())
 	if err != nil {
-		log.Error("failed to connect storage", "error", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to connect storage: %w", err)
 	}
 	log.Info("connect to db is successful", "host", cfg.Host_db)
-	_ = storage
 
-	http.HandleFunc("/api/actors", handler.ActorsHandler(storage))
-	http.HandleFunc("/api/actor", handler.ActorHandler(storage, cfg))
-	http.HandleFunc("/api/movie", handler.MovieHandler(storage, cfg))
-	http.HandleFunc("/api/movies/byTitleFragment", handler.FindMoviesByTitleFragmentHandler(storage))
-	http.HandleFunc("/api/movies/byActorNameFragment", handler.FindMoviesByActorNameFragmentHandler(storage))
-	http.Handle("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL("http://localhost:8080/swagger/doc.json"), //The url pointing to API definition
-	))
+	authenticator, err := auth.New(storage, cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.JWTActiveKey == config.EphemeralJWTKeyID {
+		log.Warn("JWT keys are not configured, tokens are signed with a random key and expire on restart")
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Error("failed to flush spans", "error", err)
+		}
+	}()
+	log.Info("tracing is set up", "exporter", cfg.TracingExporter)
+
+	proxies, err := auth.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	rt := routes(cfg, storage, authenticator)
+	rt.Use(middleware.RealIP(proxies), middleware.RequestID, middleware.Tracing, middleware.Logger(log), middleware.Metrics, middleware.Recover)
+	// CORS идёт последним, чтобы ответы на preflight-запросы тоже попадали в лог и метрики
+	if len(cfg.CORSAllowedOrigins) > 0 {
+		rt.Use(middleware.CORS(cfg.CORS))
+		log.Info("cors is enabled", "origins", cfg.CORSAllowedOrigins)
+	}
+
+	if cfg.SwaggerEnabled {
+		docURL, err := setupSwagger(cfg.PublicURL)
+		if err != nil {
+			return err
+		}
+		rt.Handle(http.MethodGet, "/swagger/", httpSwagger.Handler(
+			httpSwagger.URL(docURL), //The url pointing to API definition
+		))
+		log.Info("swagger is enabled", "url", docURL)
+	}
 
-	log.Info("Сервер запущен на :8080")
-	http.ListenAndServe(":8080", nil)
+	errc := make(chan error, 2)
+	if cfg.MetricsEnabled {
+		// /metrics не требует авторизации и отдаётся на отдельном внутреннем адресе, а не рядом с API
+		prometheus.MustRegister(storage.Collector())
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", promhttp.Handler())
+		go func() { errc <- http.ListenAndServe(cfg.MetricsAddress, mux) }()
+		log.Info("metrics are enabled", "address", cfg.MetricsAddress, "path", "/metrics")
+	}
 
+	log.Info("Сервер запущен", "address", cfg.Address, "public_url", cfg.PublicURL)
+	go func() { errc <- http.ListenAndServe(cfg.Address, rt) }()
+	return <-errc
 }
 
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
-
-	switch env {
-	case "dev":
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
-		)
-	case "prod":
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
-		)
+// setupSwagger points the generated spec at the public base URL of the service
+// and returns the URL of the spec itself.
+func setupSwagger(publicURL string) (string, error) {
+	u, err := url.Parse(publicURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid public url %q", publicURL)
 	}
+	basePath := strings.TrimRight(u.Path, "/")
 
-	return log
+	docs.SwaggerInfo.Host = u.Host
+	docs.SwaggerInfo.Schemes = []string{u.Scheme}
+	docs.SwaggerInfo.BasePath = basePath + "/"
+
+	return u.Scheme + "://" + u.Host + basePath + "/swagger/doc.json", nil
+}
 
+func setupLogger(level slog.Level) *slog.Logger {
+	return slog.New(
+		slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level}),
+	)
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/P1coFly/vk_movies/internal/postgresql/migrations"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+)
+
+// migrate implements the `migrate up|down [N]|status` command.
+func migrate(cfg *config.Config, log *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New("migrate: expected up, down or status")
+	}
+
+	storage, err := postgresql.New(cfg.StorageDSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect storage: %w", err)
+	}
+
+	migrator, err := storage.NewMigrator(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			log.Info("migration applied", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			log.Info("schema is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("migrate down: invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			log.Info("migration rolled back", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			return err
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s %s\n", st.Version, st.Name, applied)
+		}
+	default:
+		return fmt.Errorf("migrate: unknown subcommand %q", args[0])
+	}
+
+	return nil
+}
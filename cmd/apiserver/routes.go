@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/P1coFly/vk_movies/internal/http-server/handler"
+	"github.com/P1coFly/vk_movies/internal/http-server/middleware"
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/P1coFly/vk_movies/internal/models/apikey"
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+)
+
+// routes builds the api router. Every route runs behind the route middleware, usually
+// an access check, then the rate limit configured for its path.
+func routes(cfg *config.Config, storage *postgresql.Storage, a *auth.Authenticator) *router.Router {
+	rt := router.New()
+
+	// Методы одного пути делят общий лимит
+	limiters := map[string]router.Middleware{}
+	handle := func(method, path string, h http.HandlerFunc, m ...router.Middleware) {
+		if limit, ok := cfg.RouteLimit(path); ok {
+			if _, ok := limiters[path]; !ok {
+				limiters[path] = middleware.RateLimit(middleware.NewRateLimiter(limit))
+			}
+			// Лимит идёт после проверки доступа: отдельный счётчик получает только проверенный API-ключ
+			m = append(m, limiters[path])
+		}
+		rt.HandleFunc(method, path, h, m...)
+	}
+
+	read := middleware.Public(a, apikey.ScopeCatalogRead)
+	writeActors := middleware.Scoped(a, apikey.ScopeActorsWrite)
+	writeMovies := middleware.Scoped(a, apikey.ScopeMoviesWrite)
+	signedIn := middleware.Authenticated(a)
+	admin := middleware.Authenticated(a, user.RoleAdmin)
+
+	handle(http.MethodGet, "/api/actors", handler.ActorsHandler(storage), read)
+	handle(http.MethodPost, "/api/actors", handler.CreateActorHandler(storage), writeActors)
+	handle(http.MethodGet, "/api/actors/{id}", handler.GetActorHandler(storage), read)
+	handle(http.MethodPatch, "/api/actors/{id}", handler.UpdateActorHandler(storage), writeActors)
+	handle(http.MethodDelete, "/api/actors/{id}", handler.DeleteActorHandler(storage), writeActors)
+	handle(http.MethodGet, "/api/actors/{id}/movies", handler.ActorMoviesHandler(storage), read)
+
+	handle(http.MethodGet, "/api/movies", handler.MoviesHandler(storage), read)
+	handle(http.MethodPost, "/api/movies", handler.CreateMovieHandler(storage), writeMovies)
+	handle(http.MethodGet, "/api/movies/{id}", handler.GetMovieHandler(storage), read)
+	handle(http.MethodPatch, "/api/movies/{id}", handler.UpdateMovieHandler(storage), writeMovies)
+	handle(http.MethodDelete, "/api/movies/{id}", handler.DeleteMovieHandler(storage), writeMovies)
+	handle(http.MethodPut, "/api/movies/{id}/credits", handler.SetMovieCreditsHandler(storage), writeMovies)
+	handle(http.MethodGet, "/api/movies/{id}/reviews", handler.MovieReviewsHandler(storage), read)
+	handle(http.MethodPost, "/api/movies/{id}/reviews", handler.CreateReviewHandler(storage), signedIn)
+	handle(http.MethodPatch, "/api/reviews/{id}", handler.UpdateReviewHandler(storage), signedIn)
+	handle(http.MethodDelete, "/api/reviews/{id}", handler.DeleteReviewHandler(storage), signedIn)
+	handle(http.MethodGet, "/api/suggest", handler.SuggestHandler(storage, cfg.SuggestCacheTTL, cfg.SuggestCacheSize), read)
+	handle(http.MethodGet, "/api/movies/search", handler.SearchMoviesHandler(storage), read)
+	handle(http.MethodGet, "/api/movies/byTitleFragment", handler.FindMoviesByTitleFragmentHandler(storage), read)
+	handle(http.MethodGet, "/api/movies/byActorNameFragment", handler.FindMoviesByActorNameFragmentHandler(storage, cfg.ActorSimilarityThreshold), read)
+
+	handle(http.MethodGet, "/api/genres", handler.GenresHandler(storage), read)
+	handle(http.MethodPost, "/api/genres", handler.CreateGenreHandler(storage), admin)
+	handle(http.MethodGet, "/api/genres/{id}", handler.GetGenreHandler(storage), read)
+	handle(http.MethodPatch, "/api/genres/{id}", handler.UpdateGenreHandler(storage), admin)
+	handle(http.MethodDelete, "/api/genres/{id}", handler.DeleteGenreHandler(storage), admin)
+
+	handle(http.MethodPost, "/api/register", handler.RegisterHandler(storage))
+	handle(http.MethodPost, "/api/login", handler.LoginHandler(a))
+	handle(http.MethodPost, "/api/token/refresh", handler.RefreshHandler(a))
+	handle(http.MethodPost, "/api/logout", handler.LogoutHandler(a), signedIn)
+	handle(http.MethodGet, "/api/me", handler.MeHandler(storage), signedIn)
+	handle(http.MethodPut, "/api/me/password", handler.ChangePasswordHandler(storage), signedIn)
+	handle(http.MethodPost, "/api/users", handler.UsersHandler(storage), admin)
+
+	handle(http.MethodGet, "/api/apikeys", handler.APIKeysHandler(storage), admin)
+	handle(http.MethodPost, "/api/apikeys", handler.CreateAPIKeyHandler(a), admin)
+	handle(http.MethodDelete, "/api/apikeys/{id}", handler.RevokeAPIKeyHandler(storage), admin)
+
+	return rt
+}
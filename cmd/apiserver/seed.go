@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/P1coFly/vk_movies/internal/logger"
+	"github.com/P1coFly/vk_movies/internal/seed"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+)
+
+const defaultFixtures = "fixtures/dev"
+
+// seedFixtures implements the `seed [PATH...]` command.
+func seedFixtures(cfg *config.Config, log *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		args = []string{defaultFixtures}
+	}
+
+	storage, err := postgresql.New(cfg.StorageDSN())
+	if err != nil {
+		return fmt.Errorf("failed to connect storage: %w", err)
+	}
+
+	ctx := logger.With(context.Background(), log)
+	for _, path := range args {
+		fixtures, err := seed.Load(path)
+		if err != nil {
+			return err
+		}
+
+		res, err := seed.Apply(ctx, storage, fixtures)
+		if err != nil {
+			return err
+		}
+		log.Info("fixtures loaded", "path", path,
+			"actors_created", res.ActorsCreated, "actors_skipped", res.ActorsSkipped,
+			"movies_created", res.MoviesCreated, "movies_skipped", res.MoviesSkipped)
+	}
+
+	return nil
+}
@@ -15,8 +15,44 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/actor": {
+        "/api/actors": {
+            "get": {
+                "description": "Получение списка всех актеров из базы данных",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Actors"
+                ],
+                "summary": "Получение списка актеров",
+                "operationId": "getActors",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/actor.Actor"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "ApiKeyHeader": []
+                    }
+                ],
                 "description": "Создание нового актера в базе данных",
                 "consumes": [
                     "application/json"
@@ -25,10 +61,21 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Actor"
+                    "Actors"
                 ],
                 "summary": "Создание актера",
                 "operationId": "createActor",
+                "parameters": [
+                    {
+                        "description": "Актер",
+                        "name": "actor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/actor.Actor"
+                        }
+                    }
+                ],
                 "responses": {
                     "201": {
                         "description": "Actor created successfully"
@@ -38,22 +85,78 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/actors/{id}": {
+            "get": {
+                "description": "Получение актера по ID. Credits — участие человека в фильмах с ролями, начиная с последних фильмов",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Actors"
+                ],
+                "summary": "Получение актера",
+                "operationId": "getActor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID актера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/actor.Actor"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
                     }
                 }
             },
             "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "ApiKeyHeader": []
+                    }
+                ],
                 "description": "Удаление актера из базы данных",
                 "tags": [
-                    "Actor"
+                    "Actors"
                 ],
                 "summary": "Удаление актера",
                 "operationId": "deleteActor",
                 "parameters": [
                     {
-                        "type": "string",
+                        "type": "integer",
                         "description": "ID актера",
-                        "name": "actorID",
-                        "in": "query",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -76,6 +179,14 @@ const docTemplate = `{
                 }
             },
             "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "ApiKeyHeader": []
+                    }
+                ],
                 "description": "Обновление существующего актера в базе данных",
                 "consumes": [
                     "application/json"
@@ -84,17 +195,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Actor"
+                    "Actors"
                 ],
                 "summary": "Обновление актера",
                 "operationId": "updateActor",
                 "parameters": [
                     {
-                        "type": "string",
+                        "type": "integer",
                         "description": "ID актера",
-                        "name": "actorID",
-                        "in": "query",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Новые данные актера",
+                        "name": "actor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/actor.Actor"
+                        }
                     }
                 ],
                 "responses": {
@@ -116,29 +236,44 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/actors": {
+        "/api/actors/{id}/movies": {
             "get": {
-                "description": "Получение списка всех актеров из базы данных",
+                "description": "Фильмография актера с именами персонажей и порядком в титрах, начиная с последних фильмов",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Actors"
                 ],
-                "summary": "Получение списка актеров",
-                "operationId": "getActors",
+                "summary": "Фильмы актера",
+                "operationId": "getActorMovies",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID актера",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/actor.Actor"
+                                "$ref": "#/definitions/movie.Appearance"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -146,51 +281,81 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/movie": {
-            "post": {
-                "description": "Создание нового фильма в базе данных",
-                "consumes": [
-                    "application/json"
+        "/api/apikeys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Получение списка API-ключей с временем последнего использования",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Movie"
+                    "APIKeys"
                 ],
-                "summary": "Создание фильма",
-                "operationId": "createMovie",
+                "summary": "Список API-ключей",
+                "operationId": "getAPIKeys",
                 "responses": {
-                    "201": {
-                        "description": "Movie created successfully"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/apikey.APIKey"
+                            }
+                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Удаление фильма из базы данных",
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создание API-ключа с указанными правами: catalog:read, movies:write, actors:write. Ключ возвращается только в этом ответе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Movie"
+                    "APIKeys"
                 ],
-                "summary": "Удаление фильма",
-                "operationId": "deleteMovie",
+                "summary": "Выдача API-ключа",
+                "operationId": "createAPIKey",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID фильма",
-                        "name": "movieID",
-                        "in": "query",
-                        "required": true
+                        "description": "Название и права ключа",
+                        "name": "apikey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.NewAPIKeyRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Movie deleted successfully"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.NewAPIKeyResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -198,39 +363,40 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Обновление существующего фильма в базе данных",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+            }
+        },
+        "/api/apikeys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "Отзыв API-ключа по идентификатору",
                 "tags": [
-                    "Movie"
+                    "APIKeys"
                 ],
-                "summary": "Обновление фильма",
-                "operationId": "updateMovie",
+                "summary": "Отзыв API-ключа",
+                "operationId": "revokeAPIKey",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "ID фильма",
-                        "name": "movieID",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "ID ключа",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Movie updated successfully"
+                    "204": {
+                        "description": "API key revoked"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -247,44 +413,85 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/moviesByActorName": {
+        "/api/genres": {
             "get": {
-                "description": "Поиск фильмов по части имени актера в базе данных",
+                "description": "Получение списка жанров по алфавиту",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
-                ],
-                "summary": "Поиск фильмов по фрагменту имени актера",
-                "operationId": "findMoviesByActorNameFragment",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Фрагмент имени актера",
-                        "name": "actorNameFragment",
-                        "in": "query",
-                        "required": true
-                    }
+                    "Genres"
                 ],
+                "summary": "Список жанров",
+                "operationId": "getGenres",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/movie.Movie"
+                                "$ref": "#/definitions/genre.Genre"
                             }
                         }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создание жанра, названия жанров уникальны без учета регистра",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Genres"
+                ],
+                "summary": "Создание жанра",
+                "operationId": "createGenre",
+                "parameters": [
+                    {
+                        "description": "Жанр",
+                        "name": "genre",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.GenreRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/genre.Genre"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
@@ -292,23 +499,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/moviesByTitle": {
+        "/api/genres/{id}": {
             "get": {
-                "description": "Поиск фильмов по части названия в базе данных",
+                "description": "Получение жанра по ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
+                    "Genres"
                 ],
-                "summary": "Поиск фильмов по фрагменту названия",
-                "operationId": "findMoviesByTitleFragment",
+                "summary": "Получение жанра",
+                "operationId": "getGenre",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Фрагмент названия фильма",
-                        "name": "titleFragment",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "ID жанра",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -316,10 +523,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/movie.Movie"
-                            }
+                            "$ref": "#/definitions/genre.Genre"
                         }
                     },
                     "400": {
@@ -328,7 +532,1198 @@ const docTemplate = `{
                             "$ref": "#/definitions/handler.ErrorResponse"
                         }
                     },
-                    "500": {
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаление жанра, фильмы его теряют",
+                "tags": [
+                    "Genres"
+                ],
+                "summary": "Удаление жанра",
+                "operationId": "deleteGenre",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID жанра",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Genre deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменение названия жанра",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Genres"
+                ],
+                "summary": "Переименование жанра",
+                "operationId": "updateGenre",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID жанра",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое название жанра",
+                        "name": "genre",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.GenreRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Genre updated successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/login": {
+            "post": {
+                "description": "Проверка логина и пароля и выдача JWT access-токена (Authorization: Bearer \u003ctoken\u003e) и refresh-токена",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Вход",
+                "operationId": "login",
+                "parameters": [
+                    {
+                        "description": "Логин и пароль",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/logout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отзыв текущего access-токена и, если передан, refresh-токена",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Выход",
+                "operationId": "logout",
+                "parameters": [
+                    {
+                        "description": "Refresh-токен",
+                        "name": "token",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Tokens revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Получение данных аутентифицированного пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Текущий пользователь",
+                "operationId": "me",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user.User"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Смена пароля аутентифицированного пользователя. Все refresh-токены и выданные ранее access-токены пользователя отзываются",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Смена пароля",
+                "operationId": "changePassword",
+                "parameters": [
+                    {
+                        "description": "Старый и новый пароль",
+                        "name": "passwords",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movies": {
+            "get": {
+                "description": "Список фильмов, удовлетворяющих всем заданным фильтрам, по умолчанию по рейтингу по убыванию.\nОбщее число подходящих фильмов возвращается в заголовке X-Total-Count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Получение списка фильмов",
+                "operationId": "getMovies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фрагмент названия",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фрагмент имени одного из актеров",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Фрагмент имени одного из режиссёров",
+                        "name": "director",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выхода не раньше, YYYY-MM-DD",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата выхода не позже, YYYY-MM-DD",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Рейтинг не ниже",
+                        "name": "rating_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Рейтинг не выше",
+                        "name": "rating_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID жанров через запятую, фильм должен иметь все",
+                        "name": "genre_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поле сортировки: title, rating, date_of_issue",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Порядок сортировки: asc, desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Число фильмов, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Сколько фильмов пропустить",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/movie.Movie"
+                            }
+                        },
+                        "headers": {
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "Число фильмов, подходящих под фильтры"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "ApiKeyHeader": []
+                    }
+                ],
+                "description": "Создание нового фильма в базе данных",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Создание фильма",
+                "operationId": "createMovie",
+                "parameters": [
+                    {
+                        "description": "Фильм",
+                        "name": "movie",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/movie.Movie"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID актеров через запятую",
+                        "name": "actorIDs",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID жанров через запятую",
+                        "name": "genreIDs",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Movie created successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movies/byActorNameFragment": {
+            "get": {
+                "description": "Нечёткий поиск фильмов по части имени актера: допускает опечатки, пробелы и написание\nкириллицей или латиницей (\"Ди Каприо\", \"DiCaprio\"). Каждый фильм возвращается один раз\nсо списком подошедших актеров в matched_actors, фильмы самых похожих актеров идут первыми.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Поиск фильмов по фрагменту имени актера",
+                "operationId": "findMoviesByActorNameFragment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фрагмент имени актера",
+                        "name": "actorNameFragment",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/movie.ActorMatch"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movies/byTitleFragment": {
+            "get": {
+                "description": "Поиск фильмов по части названия в базе данных",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Поиск фильмов по фрагменту названия",
+                "operationId": "findMoviesByTitleFragment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Фрагмент названия фильма",
+                        "name": "titleFragment",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/movie.Movie"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movies/search": {
+            "get": {
+                "description": "Поиск по названию и описанию на русском и английском, самые релевантные фильмы первыми.\nЗапрос в синтаксисе веб-поиска: слова, \"фразы в кавычках\", OR и -исключённые слова.\nВ headline и snippet найденные слова выделены тегами \u003cb\u003e\u003c/b\u003e, остальной текст экранирован как HTML.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Полнотекстовый поиск фильмов",
+                "operationId": "searchMovies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поисковый запрос",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Число фильмов, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/movie.SearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movies/{id}": {
+            "get": {
+                "description": "Получение фильма по ID вместе с участниками: актёрами, режиссёрами, сценаристами и продюсерами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Получение фильма",
+                "operationId": "getMovie",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/movie.Movie"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "ApiKeyHeader": []
+                    }
+                ],
+                "description": "Удаление фильма из базы данных",
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Удаление фильма",
+                "operationId": "deleteMovie",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Movie deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "ApiKeyHeader": []
+                    }
+                ],
+                "description": "Обновление существующего фильма в базе данных",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Обновление фильма",
+                "operationId": "updateMovie",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные фильма",
+                        "name": "movie",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/movie.Movie"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID жанров через запятую, заменяют текущие; пустое значение убирает все жанры",
+                        "name": "genreIDs",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Movie updated successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movies/{id}/credits": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "ApiKeyHeader": []
+                    }
+                ],
+                "description": "Замена всех участников фильма: актёров, режиссёров, сценаристов и продюсеров.\nРоль: actor, director, writer, producer; имя персонажа и порядок в титрах (1 — главная роль)\nуказываются только для актёров.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Установка участников фильма",
+                "operationId": "setMovieCredits",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Участники фильма",
+                        "name": "credits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.CreditRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Credits set successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/movies/{id}/reviews": {
+            "get": {
+                "description": "Получение отзывов пользователей о фильме, начиная с новых",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Отзывы о фильме",
+                "operationId": "getMovieReviews",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Число отзывов, от 1 до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Сколько отзывов пропустить",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/review.Review"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Отзыв текущего пользователя о фильме: оценка от 0 до 10 и текст. Пользователь может оставить один отзыв о фильме",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Создание отзыва",
+                "operationId": "createReview",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID фильма",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Оценка и текст",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/review.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/register": {
+            "post": {
+                "description": "Регистрация нового пользователя с ролью user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Регистрация",
+                "operationId": "register",
+                "parameters": [
+                    {
+                        "description": "Логин и пароль",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.Credentials"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/user.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/reviews/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Удаление своего отзыва; администратор может удалить любой отзыв",
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Удаление отзыва",
+                "operationId": "deleteReview",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отзыва",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Review deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Изменение оценки и текста своего отзыва",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Изменение отзыва",
+                "operationId": "updateReview",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID отзыва",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые оценка и текст",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Review updated successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/suggest": {
+            "get": {
+                "description": "Названия фильмов и имена актеров, начинающиеся с введённого текста, без учёта регистра.\nТочные совпадения и самые короткие варианты идут первыми. Ответы кэшируются на сервере,\nпоэтому изменения каталога появляются в подсказках с задержкой до SEARCH_SUGGEST_CACHE_TTL.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Search"
+                ],
+                "summary": "Подсказки при вводе",
+                "operationId": "suggest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Начало названия или имени",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Число подсказок, от 1 до 20",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/suggestion.Suggestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/handler.ErrorResponse"
@@ -336,33 +1731,501 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "actor.Actor": {
+        },
+        "/api/token/refresh": {
+            "post": {
+                "description": "Обмен refresh-токена на новую пару токенов. Refresh-токен одноразовый: повторное использование отзывает все токены этого входа",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Обновление токенов",
+                "operationId": "refreshToken",
+                "parameters": [
+                    {
+                        "description": "Refresh-токен",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/auth.TokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Создание пользователя с указанной ролью (только для администратора)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Создание пользователя",
+                "operationId": "createUser",
+                "parameters": [
+                    {
+                        "description": "Пользователь",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.NewUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/user.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "actor.Actor": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "type": "string"
+                },
+                "credits": {
+                    "description": "Credits are filled in for a single person.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/credit.Credit"
+                    }
+                },
+                "films": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "string"
+                }
+            }
+        },
+        "apikey.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apikey.Scope"
+                    }
+                }
+            }
+        },
+        "apikey.Scope": {
+            "type": "string",
+            "enum": [
+                "catalog:read",
+                "movies:write",
+                "actors:write"
+            ],
+            "x-enum-varnames": [
+                "ScopeCatalogRead",
+                "ScopeMoviesWrite",
+                "ScopeActorsWrite"
+            ]
+        },
+        "auth.TokenPair": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "refresh_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "credit.Credit": {
+            "type": "object",
+            "properties": {
+                "billing_order": {
+                    "type": "integer"
+                },
+                "character_name": {
+                    "type": "string"
+                },
+                "movie_id": {
+                    "type": "integer"
+                },
+                "movie_title": {
+                    "type": "string"
+                },
+                "person_id": {
+                    "type": "integer"
+                },
+                "person_name": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/credit.Role"
+                }
+            }
+        },
+        "credit.Role": {
+            "type": "string",
+            "enum": [
+                "actor",
+                "director",
+                "writer",
+                "producer"
+            ],
+            "x-enum-varnames": [
+                "RoleActor",
+                "RoleDirector",
+                "RoleWriter",
+                "RoleProducer"
+            ]
+        },
+        "genre.Genre": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "old_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.Credentials": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.CreditRequest": {
+            "type": "object",
+            "properties": {
+                "billing_order": {
+                    "type": "integer"
+                },
+                "character_name": {
+                    "type": "string"
+                },
+                "person_id": {
+                    "type": "integer"
+                },
+                "role": {
+                    "$ref": "#/definitions/credit.Role"
+                }
+            }
+        },
+        "handler.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.GenreRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "refresh_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/user.User"
+                }
+            }
+        },
+        "handler.NewAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apikey.Scope"
+                    }
+                }
+            }
+        },
+        "handler.NewAPIKeyResponse": {
             "type": "object",
             "properties": {
-                "birthday": {
+                "api_key": {
+                    "$ref": "#/definitions/apikey.APIKey"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.NewUserRequest": {
+            "type": "object",
+            "properties": {
+                "login": {
                     "type": "string"
                 },
-                "films": {
+                "password": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/user.Role"
+                }
+            }
+        },
+        "handler.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ReviewRequest": {
+            "type": "object",
+            "properties": {
+                "score": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "movie.ActorMatch": {
+            "type": "object",
+            "properties": {
+                "credits": {
+                    "description": "Credits are filled in for a single movie.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/credit.Credit"
+                    }
+                },
+                "date_of_issue": {
+                    "type": "string"
+                },
+                "description": {
                     "type": "string"
                 },
+                "genres": {
+                    "description": "Genres are filled in for a single movie and the movie list, they are assigned\nby ids separately from the other fields.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/genre.Genre"
+                    }
+                },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "matched_actors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/movie.MatchedActor"
+                    }
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "title": {
                     "type": "string"
                 },
-                "sex": {
+                "user_rating": {
+                    "description": "UserRating is the average score of the user reviews next to the editorial Rating,\nit is filled in with the number of reviews for a single movie and the movie list.",
+                    "type": "number"
+                },
+                "votes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "movie.Appearance": {
+            "type": "object",
+            "properties": {
+                "billing_order": {
+                    "type": "integer"
+                },
+                "character_name": {
+                    "type": "string"
+                },
+                "credits": {
+                    "description": "Credits are filled in for a single movie.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/credit.Credit"
+                    }
+                },
+                "date_of_issue": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "genres": {
+                    "description": "Genres are filled in for a single movie and the movie list, they are assigned\nby ids separately from the other fields.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/genre.Genre"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "title": {
                     "type": "string"
+                },
+                "user_rating": {
+                    "description": "UserRating is the average score of the user reviews next to the editorial Rating,\nit is filled in with the number of reviews for a single movie and the movie list.",
+                    "type": "number"
+                },
+                "votes": {
+                    "type": "integer"
                 }
             }
         },
-        "handler.ErrorResponse": {
+        "movie.MatchedActor": {
             "type": "object",
             "properties": {
-                "error": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 }
             }
@@ -370,22 +2233,189 @@ const docTemplate = `{
         "movie.Movie": {
             "type": "object",
             "properties": {
+                "credits": {
+                    "description": "Credits are filled in for a single movie.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/credit.Credit"
+                    }
+                },
+                "date_of_issue": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "genres": {
+                    "description": "Genres are filled in for a single movie and the movie list, they are assigned\nby ids separately from the other fields.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/genre.Genre"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "user_rating": {
+                    "description": "UserRating is the average score of the user reviews next to the editorial Rating,\nit is filled in with the number of reviews for a single movie and the movie list.",
+                    "type": "number"
+                },
+                "votes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "movie.SearchResult": {
+            "type": "object",
+            "properties": {
+                "credits": {
+                    "description": "Credits are filled in for a single movie.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/credit.Credit"
+                    }
+                },
                 "date_of_issue": {
                     "type": "string"
                 },
                 "description": {
                     "type": "string"
                 },
+                "genres": {
+                    "description": "Genres are filled in for a single movie and the movie list, they are assigned\nby ids separately from the other fields.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/genre.Genre"
+                    }
+                },
+                "headline": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "rank": {
+                    "type": "number"
+                },
                 "rating": {
                     "type": "number"
                 },
+                "snippet": {
+                    "type": "string"
+                },
                 "title": {
                     "type": "string"
+                },
+                "user_rating": {
+                    "description": "UserRating is the average score of the user reviews next to the editorial Rating,\nit is filled in with the number of reviews for a single movie and the movie list.",
+                    "type": "number"
+                },
+                "votes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "review.Review": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "movie_id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "suggestion.Kind": {
+            "type": "string",
+            "enum": [
+                "movie",
+                "actor"
+            ],
+            "x-enum-varnames": [
+                "KindMovie",
+                "KindActor"
+            ]
+        },
+        "suggestion.Suggestion": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/suggestion.Kind"
+                },
+                "text": {
+                    "type": "string"
                 }
             }
+        },
+        "user.Role": {
+            "type": "string",
+            "enum": [
+                "user",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "RoleUser",
+                "RoleAdmin"
+            ]
+        },
+        "user.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/user.Role"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Токен администратора из конфигурации или \"Bearer \u003caccess token\u003e\", полученный в /api/login",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
+        "ApiKeyHeader": {
+            "description": "API-ключ сервисного клиента, выданный администратором в /api/apikeys",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -394,7 +2424,7 @@ const docTemplate = `{
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:8080",
-	BasePath:         "/api",
+	BasePath:         "/",
 	Schemes:          []string{},
 	Title:            "Vk Movies API",
 	Description:      "This is a RESTful API service for managing movies and actors",
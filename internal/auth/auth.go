@@ -0,0 +1,350 @@
+// Package auth authenticates API callers and issues access and refresh tokens.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/P1coFly/vk_movies/internal/logger"
+	"github.com/P1coFly/vk_movies/internal/models/apikey"
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID int64
+	Login  string
+	Role   user.Role
+	// TokenID and TokenExpiresAt identify the access token the caller presented.
+	TokenID        string
+	TokenExpiresAt time.Time
+	// APIKeyID and Scopes are set for service clients authenticated with an API key.
+	APIKeyID int64
+	Scopes   []apikey.Scope
+}
+
+// HasScope reports whether the principal may perform actions of the scope.
+// Admins have every scope, users may only read the catalog.
+func (p Principal) HasScope(scope apikey.Scope) bool {
+	if p.APIKeyID != 0 {
+		return slices.Contains(p.Scopes, scope)
+	}
+	switch p.Role {
+	case user.RoleAdmin:
+		return true
+	case user.RoleUser:
+		return scope == apikey.ScopeCatalogRead
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored by WithPrincipal.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// TokenPair is the result of login and refresh.
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// Authenticator checks credentials of requests and manages tokens.
+type Authenticator struct {
+	storage *postgresql.Storage
+	tokens  *TokenManager
+	// adminTokenHash is compared in constant time with the hash of the presented token.
+	adminTokenHash []byte
+	refreshTTL     time.Duration
+	lockout        *Lockout
+}
+
+func New(s *postgresql.Storage, cfg *config.Config) (*Authenticator, error) {
+	const op = "auth.New"
+
+	tokens, err := NewTokenManager(cfg.JWTKeys, cfg.JWTActiveKey, cfg.AccessTTL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Authenticator{
+		storage:        s,
+		tokens:         tokens,
+		adminTokenHash: hashToken(cfg.AuthToken),
+		refreshTTL:     cfg.RefreshTTL,
+		lockout:        NewLockout(cfg.LockoutThreshold, cfg.LockoutWindow, cfg.LockoutBackoff, cfg.LockoutMaxBackoff),
+	}, nil
+}
+
+// APIKeyHeader is the header service clients pass their API key in.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix starts every API key, so leaked keys are easy to find by secret scanners.
+const apiKeyPrefix = "vkm_"
+
+// dummyPasswordHash is checked for unknown logins, so that they take as long as wrong passwords.
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := user.HashPassword("dummy password")
+	return hash
+})
+
+// Authenticate accepts an API key in the X-API-Key header, the static admin token
+// from the config or "Bearer <access token>" issued at login. Invalid credentials are
+// recorded with Failed, requests from a locked out client fail with *LockoutError.
+// An expired access token is not a failure: clients hold them legitimately until they refresh.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if err := a.CheckLockout(r); err != nil {
+		return Principal{}, err
+	}
+
+	p, err := a.authenticate(r)
+	if errors.Is(err, ErrInvalidCredentials) && !errors.Is(err, jwt.ErrTokenExpired) {
+		a.Failed(r, err)
+	}
+	return p, err
+}
+
+// CheckLockout returns a *LockoutError if the client of the request is locked out.
+func (a *Authenticator) CheckLockout(r *http.Request) error {
+	ip := ClientIP(r)
+	err := a.lockout.Check(ip)
+	if err != nil {
+		logger.FromContext(r.Context()).Info("request from locked out client refused", "ip", ip)
+	}
+	return err
+}
+
+// Failed logs a failed authentication attempt and counts it against the client.
+// The request logger adds the method and route of the request.
+func (a *Authenticator) Failed(r *http.Request, reason error) {
+	ip := ClientIP(r)
+	failures, lock := a.lockout.Fail(ip)
+
+	log := logger.FromContext(r.Context())
+	log.Warn("authentication failed",
+		"ip", ip, "reason", reason.Error(), "failures", failures)
+	if lock > 0 {
+		log.Warn("client locked out", "ip", ip, "failures", failures, "duration", lock.String())
+	}
+}
+
+func (a *Authenticator) authenticate(r *http.Request) (Principal, error) {
+	ctx := r.Context()
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return a.authenticateAPIKey(ctx, key)
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return Principal{}, ErrNoCredentials
+	}
+
+	if subtle.ConstantTimeCompare(hashToken(authHeader), a.adminTokenHash) == 1 {
+		return Principal{Login: "admin", Role: user.RoleAdmin}, nil
+	}
+
+	token, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || token == "" {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	claims, err := a.tokens.Parse(token)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: invalid subject", ErrInvalidCredentials)
+	}
+	if claims.IssuedAt == nil {
+		return Principal{}, fmt.Errorf("%w: no issue time", ErrInvalidCredentials)
+	}
+	revoked, err := a.storage.IsAccessTokenRevoked(ctx, claims.ID, userID, claims.IssuedAt.Time)
+	if err != nil {
+		return Principal{}, err
+	}
+	if revoked {
+		return Principal{}, fmt.Errorf("%w: token revoked", ErrInvalidCredentials)
+	}
+
+	return Principal{
+		UserID:         userID,
+		Login:          claims.Login,
+		Role:           claims.Role,
+		TokenID:        claims.ID,
+		TokenExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+func (a *Authenticator) authenticateAPIKey(ctx context.Context, key string) (Principal, error) {
+	k, err := a.storage.GetActiveAPIKeyByHash(ctx, hashToken(key))
+	if errors.Is(err, storage.ErrAPIKeyNotFound) {
+		return Principal{}, fmt.Errorf("%w: unknown api key", ErrInvalidCredentials)
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	if err := a.storage.TouchAPIKey(ctx, k.Id); err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{Login: k.Name, APIKeyID: k.Id, Scopes: k.Scopes}, nil
+}
+
+// CreateAPIKey generates a key for a service client. The plain key is returned only here,
+// the storage keeps its hash.
+func (a *Authenticator) CreateAPIKey(ctx context.Context, name string, scopes []apikey.Scope, createdBy int64) (string, apikey.APIKey, error) {
+	const op = "auth.CreateAPIKey"
+
+	k, err := apikey.New(name, scopes)
+	if err != nil {
+		return "", apikey.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", apikey.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+	plain := apiKeyPrefix + secret
+	k.Prefix = plain[:len(apiKeyPrefix)+8]
+	k.Hash = hashToken(plain)
+	if createdBy != 0 {
+		k.CreatedBy = &createdBy
+	}
+	k.CreatedAt = time.Now()
+
+	k.Id, err = a.storage.SaveAPIKey(ctx, *k)
+	if err != nil {
+		return "", apikey.APIKey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return plain, *k, nil
+}
+
+// Login checks the password and issues a new token pair.
+func (a *Authenticator) Login(ctx context.Context, login, password string) (TokenPair, user.User, error) {
+	const op = "auth.Login"
+
+	u, err := a.storage.GetUserByLogin(ctx, login)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		// Одинаковый ответ и время ответа для неизвестного логина и неверного пароля
+		u.PasswordHash = dummyPasswordHash()
+		u.CheckPassword(password)
+		return TokenPair{}, user.User{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+	if err != nil {
+		return TokenPair{}, u, fmt.Errorf("%s: %w", op, err)
+	}
+	if !u.CheckPassword(password) {
+		return TokenPair{}, u, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	family, err := randomToken(16)
+	if err != nil {
+		return TokenPair{}, u, fmt.Errorf("%s: %w", op, err)
+	}
+	pair, err := a.issue(ctx, u, family)
+	if err != nil {
+		return TokenPair{}, u, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pair, u, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token is
+// used up; presenting it again revokes every token issued from the same login.
+func (a *Authenticator) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	const op = "auth.Refresh"
+
+	userID, family, err := a.storage.UseRefreshToken(ctx, hashToken(refreshToken))
+	if errors.Is(err, storage.ErrTokenNotFound) || errors.Is(err, storage.ErrTokenReused) {
+		return TokenPair{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidCredentials, err)
+	}
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Роль могла измениться с момента входа, поэтому берём актуальные данные
+	u, err := a.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	pair, err := a.issue(ctx, u, family)
+	if err != nil {
+		return TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pair, nil
+}
+
+// Logout revokes the access token of the principal and, if given, the refresh token family.
+func (a *Authenticator) Logout(ctx context.Context, p Principal, refreshToken string) error {
+	const op = "auth.Logout"
+
+	if p.TokenID != "" {
+		if err := a.storage.RevokeAccessToken(ctx, p.TokenID, p.TokenExpiresAt); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if refreshToken != "" && p.UserID != 0 {
+		if err := a.storage.RevokeRefreshToken(ctx, hashToken(refreshToken), p.UserID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *Authenticator) issue(ctx context.Context, u user.User, family string) (TokenPair, error) {
+	access, claims, err := a.tokens.Issue(u)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refresh, err := randomToken(32)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refreshExpiresAt := time.Now().Add(a.refreshTTL)
+	if err := a.storage.SaveRefreshToken(ctx, hashToken(refresh), u.Id, family, refreshExpiresAt); err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:      access,
+		TokenType:        "Bearer",
+		ExpiresAt:        claims.ExpiresAt.Time,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
@@ -0,0 +1,27 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/models/apikey"
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipalHasScope(t *testing.T) {
+	admin := auth.Principal{Role: user.RoleAdmin}
+	reader := auth.Principal{Role: user.RoleUser}
+	bot := auth.Principal{APIKeyID: 1, Scopes: []apikey.Scope{apikey.ScopeCatalogRead, apikey.ScopeMoviesWrite}}
+
+	for _, scope := range apikey.Scopes {
+		assert.True(t, admin.HasScope(scope), scope)
+	}
+
+	assert.True(t, reader.HasScope(apikey.ScopeCatalogRead))
+	assert.False(t, reader.HasScope(apikey.ScopeMoviesWrite))
+
+	assert.True(t, bot.HasScope(apikey.ScopeMoviesWrite))
+	assert.False(t, bot.HasScope(apikey.ScopeActorsWrite))
+	assert.False(t, auth.Principal{}.HasScope(apikey.ScopeCatalogRead))
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies are the reverse proxies whose X-Forwarded-For header is believed.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses addresses and CIDR ranges of the proxies, e.g. 10.0.0.0/8 or 192.0.2.1.
+func ParseTrustedProxies(list []string) (TrustedProxies, error) {
+	const op = "auth.ParseTrustedProxies"
+
+	proxies := make(TrustedProxies, 0, len(list))
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if prefix, err := netip.ParsePrefix(s); err == nil {
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is neither an address nor a CIDR range", op, s)
+		}
+		addr = addr.Unmap()
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+func (t TrustedProxies) trusts(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range t {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client. Requests from a trusted proxy are attributed
+// to the rightmost X-Forwarded-For address that is not a trusted proxy itself: addresses
+// left of it are set by the client and can't be believed.
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	ip := remoteIP(r)
+	if !t.trusts(ip) {
+		return ip
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			break
+		}
+		ip = hop
+		if !t.trusts(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of ctx carrying the resolved address of the client.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the address of the client that sent the request: the one resolved
+// behind trusted proxies if it is in the request context, the peer address otherwise.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/actors", nil)
+	r.RemoteAddr = "192.0.2.10:51234"
+	assert.Equal(t, "192.0.2.10", ClientIP(r))
+
+	r.RemoteAddr = "[2001:db8::1]:443"
+	assert.Equal(t, "2001:db8::1", ClientIP(r))
+
+	r = r.WithContext(WithClientIP(r.Context(), "198.51.100.7"))
+	assert.Equal(t, "198.51.100.7", ClientIP(r))
+}
+
+func TestTrustedProxiesClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1"})
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"direct client", "198.51.100.7:1234", nil, "198.51.100.7"},
+		{"untrusted peer can't forge", "198.51.100.7:1234", []string{"203.0.113.5"}, "198.51.100.7"},
+		{"behind proxy", "192.0.2.1:1234", []string{"203.0.113.5"}, "203.0.113.5"},
+		{"chain of proxies", "10.0.0.2:1234", []string{"203.0.113.5, 10.0.0.3", "10.0.0.4"}, "203.0.113.5"},
+		{"client prepends addresses", "192.0.2.1:1234", []string{"1.2.3.4, 203.0.113.5"}, "203.0.113.5"},
+		{"garbage stops the walk", "192.0.2.1:1234", []string{"203.0.113.5, unknown"}, "192.0.2.1"},
+		{"no header", "192.0.2.1:1234", nil, "192.0.2.1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/actors", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			assert.Equal(t, tt.want, proxies.ClientIP(r))
+		})
+	}
+}
+
+func TestParseTrustedProxiesInvalid(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseTrustedProxies([]string{"proxy.local"})
+	assert.Error(t, err)
+}
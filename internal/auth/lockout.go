@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrLockedOut is returned for requests from a source locked out after repeated failures.
+var ErrLockedOut = errors.New("too many failed attempts")
+
+// LockoutError tells how long the source stays locked out.
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrLockedOut, e.RetryAfter)
+}
+
+func (e *LockoutError) Unwrap() error {
+	return ErrLockedOut
+}
+
+// Lockout counts failed attempts per source. A source reaching the threshold within
+// the window is locked out, the lockout doubles with every further failure.
+// Successful attempts do not reset the counter, otherwise a caller holding any valid
+// credentials could interleave them with guesses.
+type Lockout struct {
+	threshold  int
+	window     time.Duration
+	backoff    time.Duration
+	maxBackoff time.Duration
+	now        func() time.Time
+
+	mu        sync.Mutex
+	sources   map[string]*lockoutEntry
+	lastSweep time.Time
+}
+
+type lockoutEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+func NewLockout(threshold int, window, backoff, maxBackoff time.Duration) *Lockout {
+	return &Lockout{
+		threshold:  threshold,
+		window:     window,
+		backoff:    backoff,
+		maxBackoff: maxBackoff,
+		now:        time.Now,
+		sources:    make(map[string]*lockoutEntry),
+	}
+}
+
+// Check returns a *LockoutError while the source is locked out.
+func (l *Lockout) Check(source string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.sources[source]
+	if !ok {
+		return nil
+	}
+	if wait := e.lockedUntil.Sub(l.now()); wait > 0 {
+		return &LockoutError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// Fail records a failed attempt and returns the number of failures in the window
+// and the lockout it caused, zero while the source is under the threshold.
+func (l *Lockout) Fail(source string) (int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	e, ok := l.sources[source]
+	if !ok || now.Sub(e.lastFailure) > l.window {
+		e = &lockoutEntry{}
+		l.sources[source] = e
+	}
+	e.failures++
+	e.lastFailure = now
+
+	if e.failures < l.threshold {
+		return e.failures, 0
+	}
+
+	lock := l.backoff
+	for i := l.threshold; i < e.failures && lock < l.maxBackoff; i++ {
+		lock *= 2
+	}
+	lock = min(lock, l.maxBackoff)
+	e.lockedUntil = now.Add(lock)
+
+	return e.failures, lock
+}
+
+// sweep drops sources that are neither locked out nor have recent failures.
+// It runs at most once a window so that Fail stays cheap.
+func (l *Lockout) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	l.lastSweep = now
+
+	for source, e := range l.sources {
+		if now.Sub(e.lastFailure) > l.window && now.After(e.lockedUntil) {
+			delete(l.sources, source)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockoutBackoff(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLockout(3, 10*time.Minute, 30*time.Second, 2*time.Minute)
+	l.now = func() time.Time { return now }
+
+	for i := 1; i < 3; i++ {
+		failures, lock := l.Fail("10.0.0.1")
+		assert.Equal(t, i, failures)
+		assert.Zero(t, lock)
+	}
+	require.NoError(t, l.Check("10.0.0.1"))
+
+	_, lock := l.Fail("10.0.0.1")
+	assert.Equal(t, 30*time.Second, lock)
+
+	var lockoutErr *LockoutError
+	require.ErrorAs(t, l.Check("10.0.0.1"), &lockoutErr)
+	assert.Equal(t, 30*time.Second, lockoutErr.RetryAfter)
+	assert.ErrorIs(t, lockoutErr, ErrLockedOut)
+	assert.NoError(t, l.Check("10.0.0.2"), "other sources are not affected")
+
+	_, lock = l.Fail("10.0.0.1")
+	assert.Equal(t, time.Minute, lock)
+	_, lock = l.Fail("10.0.0.1")
+	assert.Equal(t, 2*time.Minute, lock)
+	_, lock = l.Fail("10.0.0.1")
+	assert.Equal(t, 2*time.Minute, lock, "lockout is capped")
+
+	now = now.Add(3 * time.Minute)
+	assert.NoError(t, l.Check("10.0.0.1"))
+}
+
+func TestLockoutWindowResetsFailures(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLockout(2, time.Minute, time.Second, time.Second)
+	l.now = func() time.Time { return now }
+
+	l.Fail("10.0.0.1")
+	now = now.Add(2 * time.Minute)
+
+	failures, lock := l.Fail("10.0.0.1")
+	assert.Equal(t, 1, failures)
+	assert.Zero(t, lock)
+}
+
+func TestAuthenticateLockout(t *testing.T) {
+	m, err := NewTokenManager(map[string]string{"k1": secretOld}, "k1", time.Minute)
+	require.NoError(t, err)
+	a := &Authenticator{
+		tokens:         m,
+		adminTokenHash: hashToken("admin-token"),
+		lockout:        NewLockout(2, time.Minute, time.Minute, time.Minute),
+	}
+	request := func(authorization string) *http.Request {
+		r := httptest.NewRequest("GET", "/api/actors", nil)
+		r.Header.Set("Authorization", authorization)
+		return r
+	}
+
+	// Истёкший токен не считается неудачной попыткой
+	token, _, err := m.Issue(testUser)
+	require.NoError(t, err)
+	later := *m
+	later.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	a.tokens = &later
+	for range 3 {
+		_, err = a.Authenticate(request("Bearer " + token))
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+	a.tokens = m
+
+	// Действующие учётные данные между попытками подбора не сбрасывают счётчик
+	_, err = a.Authenticate(request("Bearer forged"))
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = a.Authenticate(request("admin-token"))
+	require.NoError(t, err)
+	_, err = a.Authenticate(request("Bearer forged"))
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = a.Authenticate(request("admin-token"))
+	assert.ErrorIs(t, err, ErrLockedOut, "the client must be locked out")
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const issuer = "vk_movies"
+
+// Claims are the claims of an access token.
+type Claims struct {
+	jwt.RegisteredClaims
+	Login string    `json:"login"`
+	Role  user.Role `json:"role"`
+}
+
+// TokenManager signs and verifies JWT access tokens with a rotating key set.
+type TokenManager struct {
+	keys      map[string][]byte
+	activeKID string
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+// NewTokenManager returns a manager signing with the key activeKID of keys.
+func NewTokenManager(keys map[string]string, activeKID string, ttl time.Duration) (*TokenManager, error) {
+	const op = "auth.NewTokenManager"
+
+	if _, ok := keys[activeKID]; !ok {
+		return nil, fmt.Errorf("%s: active key %q is not in the key set", op, activeKID)
+	}
+
+	m := &TokenManager{keys: make(map[string][]byte, len(keys)), activeKID: activeKID, ttl: ttl, now: time.Now}
+	for kid, secret := range keys {
+		m.keys[kid] = []byte(secret)
+	}
+
+	return m, nil
+}
+
+// Issue returns a signed access token for the user and its claims.
+func (m *TokenManager) Issue(u user.User) (string, Claims, error) {
+	const op = "auth.TokenManager.Issue"
+
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", Claims{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := m.now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Issuer:    issuer,
+			Subject:   fmt.Sprint(u.Id),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.ttl)),
+		},
+		Login: u.Login,
+		Role:  u.Role,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = m.activeKID
+
+	signed, err := token.SignedString(m.keys[m.activeKID])
+	if err != nil {
+		return "", Claims{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return signed, claims, nil
+}
+
+// Parse verifies the signature, expiry and issuer of the token and returns its claims.
+func (m *TokenManager) Parse(token string) (*Claims, error) {
+	const op = "auth.TokenManager.Parse"
+
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := m.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(m.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if claims.ID == "" || !claims.Role.Valid() {
+		return nil, fmt.Errorf("%s: %w", op, errors.New("token has no id or role"))
+	}
+
+	return &claims, nil
+}
+
+// randomToken returns n random bytes encoded with base64url.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hash under which an opaque token is stored.
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	secretOld = "old-secret-old-secret-old-secret"
+	secretNew = "new-secret-new-secret-new-secret"
+)
+
+var testUser = user.User{Id: 42, Login: "john", Role: user.RoleAdmin}
+
+func TestTokenManagerIssueParse(t *testing.T) {
+	m, err := NewTokenManager(map[string]string{"k1": secretOld}, "k1", time.Minute)
+	require.NoError(t, err)
+
+	token, issued, err := m.Issue(testUser)
+	require.NoError(t, err)
+
+	claims, err := m.Parse(token)
+	require.NoError(t, err)
+	assert.Equal(t, issued.ID, claims.ID)
+	assert.Equal(t, "42", claims.Subject)
+	assert.Equal(t, "john", claims.Login)
+	assert.Equal(t, user.RoleAdmin, claims.Role)
+}
+
+func TestTokenManagerKeyRotation(t *testing.T) {
+	before, err := NewTokenManager(map[string]string{"old": secretOld}, "old", time.Minute)
+	require.NoError(t, err)
+	token, _, err := before.Issue(testUser)
+	require.NoError(t, err)
+
+	// Новый ключ активен, старый ещё в наборе: ранее выданные токены действительны
+	rotated, err := NewTokenManager(map[string]string{"old": secretOld, "new": secretNew}, "new", time.Minute)
+	require.NoError(t, err)
+	_, err = rotated.Parse(token)
+	assert.NoError(t, err)
+
+	// Старый ключ удалён из набора
+	after, err := NewTokenManager(map[string]string{"new": secretNew}, "new", time.Minute)
+	require.NoError(t, err)
+	_, err = after.Parse(token)
+	assert.Error(t, err)
+}
+
+func TestTokenManagerRejects(t *testing.T) {
+	m, err := NewTokenManager(map[string]string{"k1": secretOld}, "k1", time.Minute)
+	require.NoError(t, err)
+
+	t.Run("expired", func(t *testing.T) {
+		token, _, err := m.Issue(testUser)
+		require.NoError(t, err)
+
+		later := *m
+		later.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		_, err = later.Parse(token)
+		assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+	})
+
+	t.Run("wrong signature", func(t *testing.T) {
+		other, err := NewTokenManager(map[string]string{"k1": secretNew}, "k1", time.Minute)
+		require.NoError(t, err)
+		token, _, err := other.Issue(testUser)
+		require.NoError(t, err)
+
+		_, err = m.Parse(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("alg none", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{
+			RegisteredClaims: jwt.RegisteredClaims{ID: "x", Issuer: issuer, ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+			Role:             user.RoleAdmin,
+		})
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = m.Parse(signed)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown active key", func(t *testing.T) {
+		_, err := NewTokenManager(map[string]string{"k1": secretOld}, "k2", time.Minute)
+		assert.Error(t, err)
+	})
+}
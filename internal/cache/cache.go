@@ -0,0 +1,72 @@
+// Package cache is an in-process cache of values that expire a fixed time after they are set.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache keeps up to size values for ttl each. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	ttl  time.Duration
+	size int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[K]entry[V]
+}
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+func New[K comparable, V any](ttl time.Duration, size int) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:     ttl,
+		size:    size,
+		now:     time.Now,
+		entries: make(map[K]entry[V]),
+	}
+}
+
+// Get returns the value of the key unless it is missing or expired.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expires) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores the value of the key. A full cache first drops expired values and,
+// if there are none, an arbitrary one.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[key] = entry[V]{value: value, expires: now.Add(c.ttl)}
+}
+
+func (c *Cache[K, V]) evict(now time.Time) {
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) < c.size {
+		return
+	}
+	for key := range c.entries {
+		delete(c.entries, key)
+		return
+	}
+}
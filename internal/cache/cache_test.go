@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheExpires(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := New[string, int](time.Minute, 10)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a")
+	assert.False(t, ok, "value must expire after ttl")
+
+	_, ok = c.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestCacheSize(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := New[int, int](time.Minute, 2)
+	c.now = func() time.Time { return now }
+
+	c.Set(1, 1)
+	now = now.Add(time.Minute)
+	c.Set(2, 2)
+	c.Set(3, 3)
+
+	// Место освобождается за счёт истёкшего значения
+	_, ok := c.Get(2)
+	assert.True(t, ok)
+	_, ok = c.Get(3)
+	assert.True(t, ok)
+
+	c.Set(4, 4)
+	assert.Len(t, c.entries, 2)
+	_, ok = c.Get(4)
+	assert.True(t, ok)
+}
@@ -1,42 +1,468 @@
 package config
 
 import (
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+// Config is the service configuration. Every field can be set from the environment,
+// the optional YAML file from CONFIG_PATH is read first and the environment overrides it.
 type Config struct {
-	Env         string `yaml:"env"`
-	Host_db     string `yaml:"host_db"`
-	User_db     string `yaml:"user_db"`
-	Password_db string `yaml:"password_db"`
-	Name_db     string `yaml:"name_db"`
-	Admin       `yaml:"admin"`
+	Env         string `yaml:"env" env:"ENV" env-default:"prod" env-description:"dev or prod"`
+	LogLevel    string `yaml:"log_level" env:"LOG_LEVEL" env-description:"debug, info, warn or error; debug for dev and info for prod by default"`
+	Host_db     string `yaml:"host_db" env:"DB_HOST" env-default:"localhost" env-description:"database host"`
+	Port_db     int    `yaml:"port_db" env:"DB_PORT" env-default:"5432" env-description:"database port"`
+	User_db     string `yaml:"user_db" env:"DB_USER" env-default:"api_service" env-description:"database user"`
+	Password_db string `yaml:"password_db" env:"DB_PASSWORD" env-description:"database password"`
+	// PasswordFile_db is read into Password_db, e.g. a Docker or Kubernetes secret mount.
+	PasswordFile_db string `yaml:"password_db_file" env:"DB_PASSWORD_FILE" env-description:"file to read the database password from"`
+	Name_db         string `yaml:"name_db" env:"DB_NAME" env-default:"VK_MOVIES" env-description:"database name"`
+	SSLMode_db      string `yaml:"sslmode_db" env:"DB_SSLMODE" env-default:"disable" env-description:"disable, require, verify-ca or verify-full"`
+	Admin           `yaml:"admin"`
+	Auth            `yaml:"auth"`
+	HTTPServer      `yaml:"http"`
+	RateLimit       `yaml:"rate_limit"`
+	CORS            `yaml:"cors"`
+	Tracing         `yaml:"tracing"`
+	Search          `yaml:"search"`
 }
 
+// DefaultAuthToken is the admin token used when none is configured, it is refused in prod.
+const DefaultAuthToken = "token"
+
+const redacted = "[REDACTED]"
+
 type Admin struct {
-	AuthToken string `yaml:"auth_token"`
+	AuthToken     string `yaml:"auth_token" env:"AUTH_TOKEN" env-default:"token" env-description:"admin token for the Authorization header"`
+	AuthTokenFile string `yaml:"auth_token_file" env:"AUTH_TOKEN_FILE" env-description:"file to read the admin token from"`
+}
+
+// EphemeralJWTKeyID is the id of the random signing key generated in dev when no keys are configured.
+const EphemeralJWTKeyID = "ephemeral"
+
+type Auth struct {
+	AccessTTL  time.Duration `yaml:"access_ttl" env:"AUTH_ACCESS_TTL" env-default:"15m" env-description:"lifetime of a JWT access token"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl" env:"AUTH_REFRESH_TTL" env-default:"720h" env-description:"lifetime of a refresh token"`
+	// JWTKeys maps key ids to HMAC secrets. Tokens are signed with JWTActiveKey and
+	// verified with any key of the set, so keys can be rotated without logging users out.
+	JWTKeys      map[string]string `yaml:"jwt_keys" env:"JWT_KEYS" env-description:"JWT signing keys as kid:secret pairs separated by commas"`
+	JWTKeysFile  string            `yaml:"jwt_keys_file" env:"JWT_KEYS_FILE" env-description:"file with JWT signing keys, one kid:secret pair per line"`
+	JWTActiveKey string            `yaml:"jwt_active_key" env:"JWT_ACTIVE_KEY" env-description:"id of the key new tokens are signed with"`
+	// A source making LockoutThreshold failed attempts within LockoutWindow is locked out
+	// for LockoutBackoff, doubled with every further failure up to LockoutMaxBackoff.
+	LockoutThreshold  int           `yaml:"lockout_threshold" env:"AUTH_LOCKOUT_THRESHOLD" env-default:"5" env-description:"failed auth attempts from one client before it is locked out"`
+	LockoutWindow     time.Duration `yaml:"lockout_window" env:"AUTH_LOCKOUT_WINDOW" env-default:"15m" env-description:"period failed auth attempts are counted in"`
+	LockoutBackoff    time.Duration `yaml:"lockout_backoff" env:"AUTH_LOCKOUT_BACKOFF" env-default:"30s" env-description:"first lockout duration"`
+	LockoutMaxBackoff time.Duration `yaml:"lockout_max_backoff" env:"AUTH_LOCKOUT_MAX_BACKOFF" env-default:"15m" env-description:"maximum lockout duration"`
+}
+
+type HTTPServer struct {
+	Address        string `yaml:"address" env:"HTTP_ADDRESS" env-default:":8080" env-description:"address the http server listens on"`
+	PublicURL      string `yaml:"public_url" env:"PUBLIC_URL" env-default:"http://localhost:8080" env-description:"base url the api is reachable at by clients, used for swagger"`
+	SwaggerEnabled bool   `yaml:"swagger_enabled" env:"SWAGGER_ENABLED" env-default:"false" env-description:"serve swagger ui at /swagger/"`
+	MetricsEnabled bool   `yaml:"metrics_enabled" env:"METRICS_ENABLED" env-default:"false" env-description:"serve prometheus metrics at /metrics"`
+	// Metrics are served without authentication, so they get a listener of their own
+	// that is not reachable by api clients.
+	MetricsAddress string `yaml:"metrics_address" env:"METRICS_ADDRESS" env-default:"localhost:9090" env-description:"internal address /metrics is served on, apart from the api"`
+	// X-Forwarded-For is believed only in requests from TrustedProxies, clients behind
+	// them are told apart by the address the proxies forward.
+	TrustedProxies []string `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" env-description:"addresses or CIDR ranges of reverse proxies whose X-Forwarded-For is trusted, separated by commas"`
 }
 
-func MustLoad() *Config {
-	configPath := os.Getenv("CONFIG_PATH")
-	log.Printf("%s", configPath)
-	if configPath == "" {
-		log.Fatal("CONFIG_PATH is not set")
+// RateLimit sets how many requests a client may make, see ParseLimit for the format.
+// Routes without their own limit get RateLimitDefault, an empty default means no limit.
+type RateLimit struct {
+	RateLimitDefault string            `yaml:"default" env:"RATE_LIMIT_DEFAULT" env-description:"limit of every api route without its own limit, e.g. 120/1m"`
+	RateLimitRoutes  map[string]string `yaml:"routes" env:"RATE_LIMIT_ROUTES" env-default:"/api/movies/search:60/1m,/api/movies/byTitleFragment:30/1m,/api/movies/byActorNameFragment:30/1m,/api/login:10/1m,/api/register:5/1m,/api/token/refresh:30/1m" env-description:"per route limits as route:limit pairs separated by commas"`
+}
+
+// CORS lets browser clients on other origins call the API. CORS is off while
+// CORSAllowedOrigins is empty, "*" allows any origin.
+type CORS struct {
+	CORSAllowedOrigins   []string      `yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS" env-description:"origins allowed to call the api, e.g. https://movies.example.com, or *"`
+	CORSAllowedMethods   []string      `yaml:"allowed_methods" env:"CORS_ALLOWED_METHODS" env-default:"GET,POST,PUT,PATCH,DELETE" env-description:"methods allowed in cross-origin requests"`
+	CORSAllowedHeaders   []string      `yaml:"allowed_headers" env:"CORS_ALLOWED_HEADERS" env-default:"Authorization,Content-Type,X-API-Key,X-Request-ID" env-description:"request headers allowed in cross-origin requests"`
+	CORSExposedHeaders   []string      `yaml:"exposed_headers" env:"CORS_EXPOSED_HEADERS" env-default:"X-Request-ID,X-Total-Count,Retry-After,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,RateLimit-Policy" env-description:"response headers readable by browser clients"`
+	CORSAllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" env-default:"false" env-description:"allow cookies and the Authorization header in cross-origin requests"`
+	CORSMaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" env-default:"10m" env-description:"how long browsers may cache a preflight response"`
+}
+
+// Span exporters of Tracing.
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// Tracing exports OpenTelemetry spans of requests and storage calls. With the none exporter
+// spans are still created and trace context is propagated, but nothing is sent.
+type Tracing struct {
+	TracingExporter     string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none" env-description:"span exporter: none, stdout or otlp"`
+	TracingOTLPEndpoint string  `yaml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" env-default:"localhost:4318" env-description:"host:port of the OTLP/HTTP collector"`
+	TracingOTLPInsecure bool    `yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE" env-default:"false" env-description:"send spans to the collector over plain http"`
+	TracingSampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1" env-description:"share of new traces that are sampled, from 0 to 1"`
+	TracingServiceName  string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" env-default:"vk_movies" env-description:"service.name of the exported spans"`
+}
+
+// Search tunes the catalog search.
+type Search struct {
+	// ActorSimilarityThreshold is the minimal pg_trgm word similarity of the searched
+	// fragment to an actor name: lower finds more misspelled names and more noise.
+	ActorSimilarityThreshold float64 `yaml:"actor_similarity_threshold" env:"SEARCH_ACTOR_SIMILARITY_THRESHOLD" env-default:"0.4" env-description:"minimal similarity of a searched name to an actor name, from 0 to 1"`
+	// Suggestions are cached in process, so changes to the catalog show up in them
+	// within SuggestCacheTTL.
+	SuggestCacheTTL  time.Duration `yaml:"suggest_cache_ttl" env:"SEARCH_SUGGEST_CACHE_TTL" env-default:"1m" env-description:"how long suggestions for a prefix are cached"`
+	SuggestCacheSize int           `yaml:"suggest_cache_size" env:"SEARCH_SUGGEST_CACHE_SIZE" env-default:"10000" env-description:"number of prefixes suggestions are cached for"`
+}
+
+// Limit is the number of requests allowed per period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses "<requests>/<period>", e.g. "30/1m" or "5/s".
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q, expected requests/period", s)
 	}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		log.Fatalf("config file does not exist: %s", configPath)
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("invalid limit %q: requests must be a positive number", s)
+	}
+	// "5/s" is a shorthand for "5/1s"
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: period must be a positive duration", s)
 	}
 
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// RouteLimit returns the limit of the route, ok is false if the route is not limited.
+func (c *Config) RouteLimit(route string) (limit Limit, ok bool) {
+	s, ok := c.RateLimitRoutes[route]
+	if !ok {
+		s = c.RateLimitDefault
+	}
+	if s == "" {
+		return Limit{}, false
+	}
+
+	limit, err := ParseLimit(s)
+	return limit, err == nil
+}
+
+// Load reads the configuration from the file set in CONFIG_PATH (if any) and from
+// environment variables, applies defaults and validates the result.
+func Load() (*Config, error) {
+	const op = "config.Load"
+
 	var cfg Config
 
-	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
-		log.Fatalf("can't read config: %s", err)
+	if configPath := os.Getenv("CONFIG_PATH"); configPath != "" {
+		if _, err := os.Stat(configPath); err != nil {
+			return nil, fmt.Errorf("%s: config file: %w", op, err)
+		}
+		if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
+			return nil, fmt.Errorf("%s: can't read config: %w", op, err)
+		}
+	} else if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: can't read environment: %w", op, err)
+	}
+
+	if err := cfg.readSecretFiles(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// В dev без настроенных ключей подписываем токены случайным ключом,
+	// выданные токены перестают действовать после перезапуска
+	if len(cfg.JWTKeys) == 0 && cfg.Env == "dev" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		cfg.JWTKeys = map[string]string{EphemeralJWTKeyID: hex.EncodeToString(secret)}
+		cfg.JWTActiveKey = EphemeralJWTKeyID
+	}
+	if cfg.JWTActiveKey == "" && len(cfg.JWTKeys) == 1 {
+		for kid := range cfg.JWTKeys {
+			cfg.JWTActiveKey = kid
+		}
+	}
+
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = "info"
+		if cfg.Env == "dev" {
+			cfg.LogLevel = "debug"
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks all fields and reports every problem found.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Env != "dev" && c.Env != "prod" {
+		errs = append(errs, fmt.Errorf("env must be dev or prod, got %q", c.Env))
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs = append(errs, fmt.Errorf("log_level must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+
+	if c.Host_db == "" {
+		errs = append(errs, errors.New("host_db must not be empty"))
+	}
+	if c.Port_db < 1 || c.Port_db > 65535 {
+		errs = append(errs, fmt.Errorf("port_db must be in the range from 1 to 65535, got %d", c.Port_db))
+	}
+	if c.User_db == "" {
+		errs = append(errs, errors.New("user_db must not be empty"))
+	}
+	if c.Name_db == "" {
+		errs = append(errs, errors.New("name_db must not be empty"))
+	}
+	switch c.SSLMode_db {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("sslmode_db must be disable, require, verify-ca or verify-full, got %q", c.SSLMode_db))
+	}
+
+	if c.AuthToken == "" {
+		errs = append(errs, errors.New("admin.auth_token must not be empty"))
+	}
+	if c.Env == "prod" && c.AuthToken == DefaultAuthToken {
+		errs = append(errs, errors.New("admin.auth_token must be changed from the default value in prod"))
+	}
+
+	if c.AccessTTL <= 0 {
+		errs = append(errs, fmt.Errorf("auth.access_ttl must be positive, got %s", c.AccessTTL))
+	}
+	if c.RefreshTTL <= c.AccessTTL {
+		errs = append(errs, fmt.Errorf("auth.refresh_ttl must be greater than auth.access_ttl, got %s", c.RefreshTTL))
+	}
+	if len(c.JWTKeys) == 0 {
+		errs = append(errs, errors.New("auth.jwt_keys must contain at least one key"))
+	}
+	for kid, secret := range c.JWTKeys {
+		if len(secret) < 32 {
+			errs = append(errs, fmt.Errorf("auth.jwt_keys: key %q must be at least 32 bytes long", kid))
+		}
+	}
+	if _, ok := c.JWTKeys[c.JWTActiveKey]; !ok && len(c.JWTKeys) > 0 {
+		errs = append(errs, fmt.Errorf("auth.jwt_active_key %q is not in auth.jwt_keys", c.JWTActiveKey))
+	}
+	if c.LockoutThreshold < 1 {
+		errs = append(errs, fmt.Errorf("auth.lockout_threshold must be at least 1, got %d", c.LockoutThreshold))
+	}
+	if c.LockoutWindow <= 0 || c.LockoutBackoff <= 0 {
+		errs = append(errs, errors.New("auth.lockout_window and auth.lockout_backoff must be positive"))
+	}
+	if c.LockoutMaxBackoff < c.LockoutBackoff {
+		errs = append(errs, fmt.Errorf("auth.lockout_max_backoff must not be less than auth.lockout_backoff, got %s", c.LockoutMaxBackoff))
+	}
+
+	if c.RateLimitDefault != "" {
+		if _, err := ParseLimit(c.RateLimitDefault); err != nil {
+			errs = append(errs, fmt.Errorf("rate_limit.default: %w", err))
+		}
+	}
+	for route, limit := range c.RateLimitRoutes {
+		if _, err := ParseLimit(limit); err != nil {
+			errs = append(errs, fmt.Errorf("rate_limit.routes: %s: %w", route, err))
+		}
+	}
+
+	for _, origin := range c.CORSAllowedOrigins {
+		if origin == "*" {
+			if c.CORSAllowCredentials {
+				errs = append(errs, errors.New("cors.allowed_origins must list origins when cors.allow_credentials is set, got *"))
+			}
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			errs = append(errs, fmt.Errorf("cors.allowed_origins: %q must be scheme://host[:port]", origin))
+		}
+	}
+	if c.CORSMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("cors.max_age must not be negative, got %s", c.CORSMaxAge))
+	}
+
+	switch c.TracingExporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterOTLP:
+		if _, _, err := net.SplitHostPort(c.TracingOTLPEndpoint); err != nil {
+			errs = append(errs, fmt.Errorf("tracing.otlp_endpoint must be host:port, got %q", c.TracingOTLPEndpoint))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be none, stdout or otlp, got %q", c.TracingExporter))
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("tracing.sample_ratio must be from 0 to 1, got %v", c.TracingSampleRatio))
+	}
+
+	if c.ActorSimilarityThreshold <= 0 || c.ActorSimilarityThreshold > 1 {
+		errs = append(errs, fmt.Errorf("search.actor_similarity_threshold must be greater than 0 and at most 1, got %v", c.ActorSimilarityThreshold))
+	}
+	if c.SuggestCacheTTL <= 0 || c.SuggestCacheSize < 1 {
+		errs = append(errs, errors.New("search.suggest_cache_ttl and search.suggest_cache_size must be positive"))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		errs = append(errs, fmt.Errorf("http.address must be host:port, got %q", c.Address))
+	}
+	if c.MetricsEnabled {
+		if _, _, err := net.SplitHostPort(c.MetricsAddress); err != nil {
+			errs = append(errs, fmt.Errorf("http.metrics_address must be host:port, got %q", c.MetricsAddress))
+		} else if c.MetricsAddress == c.Address {
+			errs = append(errs, errors.New("http.metrics_address must differ from http.address"))
+		}
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err != nil {
+			if _, err := netip.ParseAddr(proxy); err != nil {
+				errs = append(errs, fmt.Errorf("http.trusted_proxies: %q must be an address or a CIDR range", proxy))
+			}
+		}
+	}
+	if u, err := url.Parse(c.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("http.public_url must be an absolute http(s) url, got %q", c.PublicURL))
+	}
+
+	return errors.Join(errs...)
+}
+
+// readSecretFiles replaces secrets with the contents of their *_FILE counterparts.
+func (c *Config) readSecretFiles() error {
+	secrets := []struct {
+		file  string
+		value *string
+	}{
+		{c.PasswordFile_db, &c.Password_db},
+		{c.AuthTokenFile, &c.AuthToken},
+	}
+
+	for _, secret := range secrets {
+		if secret.file == "" {
+			continue
+		}
+		data, err := os.ReadFile(secret.file)
+		if err != nil {
+			return fmt.Errorf("can't read secret: %w", err)
+		}
+		*secret.value = strings.TrimRight(string(data), "\r\n")
+	}
+
+	if c.JWTKeysFile != "" {
+		data, err := os.ReadFile(c.JWTKeysFile)
+		if err != nil {
+			return fmt.Errorf("can't read secret: %w", err)
+		}
+		keys, err := parseKeySet(string(data))
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.JWTKeysFile, err)
+		}
+		c.JWTKeys = keys
+	}
+
+	return nil
+}
+
+// parseKeySet parses kid:secret pairs separated by commas or new lines.
+func parseKeySet(data string) (map[string]string, error) {
+	keys := map[string]string{}
+	for _, pair := range strings.FieldsFunc(data, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+		kid, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || kid == "" {
+			return nil, fmt.Errorf("invalid key %q, expected kid:secret", kid)
+		}
+		keys[kid] = secret
 	}
+	return keys, nil
+}
 
-	return &cfg
+// Redacted returns a copy of the config with secrets replaced by a placeholder.
+func (c Config) Redacted() Config {
+	if c.Password_db != "" {
+		c.Password_db = redacted
+	}
+	if c.AuthToken != "" {
+		c.AuthToken = redacted
+	}
+	if len(c.JWTKeys) > 0 {
+		keys := make(map[string]string, len(c.JWTKeys))
+		for kid := range c.JWTKeys {
+			keys[kid] = redacted
+		}
+		c.JWTKeys = keys
+	}
+	return c
+}
+
+// plainConfig has the fields of Config without its methods, so formatting it does not recurse.
+type plainConfig Config
+
+// String implements fmt.Stringer without exposing secrets.
+func (c Config) String() string {
+	return fmt.Sprintf("%+v", plainConfig(c.Redacted()))
+}
+
+// GoString implements fmt.GoStringer without exposing secrets.
+func (c Config) GoString() string {
+	return fmt.Sprintf("%#v", plainConfig(c.Redacted()))
+}
+
+// LogValue implements slog.LogValuer without exposing secrets.
+func (c Config) LogValue() slog.Value {
+	return slog.AnyValue(plainConfig(c.Redacted()))
+}
+
+// SlogLevel returns the parsed log level.
+func (c *Config) SlogLevel() slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(c.LogLevel))
+	return level
+}
+
+// StorageDSN returns the connection string for the PostgreSQL storage.
+func (c *Config) StorageDSN() string {
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User_db, c.Password_db),
+		Host:     net.JoinHostPort(c.Host_db, strconv.Itoa(c.Port_db)),
+		Path:     "/" + c.Name_db,
+		RawQuery: "sslmode=" + url.QueryEscape(c.SSLMode_db),
+	}
+	return u.String()
+}
 
+// Usage returns the description of the supported environment variables.
+func Usage() string {
+	header := "Environment variables:"
+	desc, _ := cleanenv.GetDescription(&Config{}, &header)
+	return desc
 }
@@ -0,0 +1,306 @@
+package config_test
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setEnv clears CONFIG_PATH and sets the variables without which prod refuses to start.
+func setEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_PATH", "")
+	t.Setenv("AUTH_TOKEN", "secret-token")
+	t.Setenv("JWT_KEYS", "k1:"+testJWTSecret)
+}
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef"
+
+func TestLoadDefaults(t *testing.T) {
+	setEnv(t)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod", cfg.Env)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, slog.LevelInfo, cfg.SlogLevel())
+	assert.Equal(t, "localhost", cfg.Host_db)
+	assert.Equal(t, 5432, cfg.Port_db)
+	assert.Equal(t, ":8080", cfg.Address)
+	assert.Equal(t, "http://localhost:8080", cfg.PublicURL)
+	assert.False(t, cfg.SwaggerEnabled)
+
+	fromEnv := cfg.RateLimitRoutes
+	t.Setenv("CONFIG_PATH", filepath.Join("..", "..", "config", "config.yml"))
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	// Без лимита на входе пароли можно перебирать без ограничений
+	for _, route := range []string{"/api/login", "/api/register", "/api/token/refresh"} {
+		assert.Contains(t, fromEnv, route)
+		assert.Equal(t, cfg.RateLimitRoutes[route], fromEnv[route], "the environment and config.yml agree on %s", route)
+	}
+}
+
+func TestLoadFromEnv(t *testing.T) {
+	setEnv(t)
+	t.Setenv("ENV", "dev")
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("DB_PASSWORD", "p@ss word")
+	t.Setenv("HTTP_ADDRESS", "0.0.0.0:9090")
+	t.Setenv("SWAGGER_ENABLED", "true")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "0.0.0.0:9090", cfg.Address)
+	assert.True(t, cfg.SwaggerEnabled)
+	assert.Equal(t, "postgres://api_service:p%40ss%20word@db:5432/VK_MOVIES?sslmode=disable", cfg.StorageDSN())
+}
+
+func TestLoadFileWithEnvOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("env: dev\nhost_db: filehost\nlog_level: warn\n"), 0o644))
+	t.Setenv("CONFIG_PATH", path)
+	t.Setenv("DB_HOST", "envhost")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "dev", cfg.Env)
+	assert.Equal(t, "envhost", cfg.Host_db)
+	assert.Equal(t, slog.LevelWarn, cfg.SlogLevel())
+}
+
+func TestLoadRepoConfigFile(t *testing.T) {
+	setEnv(t)
+	t.Setenv("CONFIG_PATH", filepath.Join("..", "..", "config", "config.yml"))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "dev", cfg.Env)
+	assert.Equal(t, "http://localhost:8080", cfg.PublicURL)
+	assert.Equal(t, 5, cfg.LockoutThreshold)
+	assert.True(t, cfg.SwaggerEnabled)
+	// Образ собирается с этим файлом, метрики должны быть доступны из сети контейнеров
+	assert.Equal(t, ":9090", cfg.MetricsAddress)
+}
+
+func TestLoadValidation(t *testing.T) {
+	tests := map[string]string{
+		"ENV":                  "staging",
+		"LOG_LEVEL":            "verbose",
+		"DB_PORT":              "70000",
+		"DB_SSLMODE":           "sometimes",
+		"HTTP_ADDRESS":         "8080",
+		"PUBLIC_URL":           "localhost:8080",
+		"HTTP_TRUSTED_PROXIES": "proxy.local",
+
+		"AUTH_LOCKOUT_THRESHOLD":            "0",
+		"AUTH_LOCKOUT_MAX_BACKOFF":          "10s",
+		"RATE_LIMIT_DEFAULT":                "fast",
+		"RATE_LIMIT_ROUTES":                 "/api/actors:10/0s",
+		"CORS_ALLOWED_ORIGINS":              "movies.example.com",
+		"TRACING_EXPORTER":                  "jaeger",
+		"TRACING_SAMPLE_RATIO":              "1.5",
+		"SEARCH_ACTOR_SIMILARITY_THRESHOLD": "1.2",
+		"SEARCH_SUGGEST_CACHE_SIZE":         "0",
+	}
+
+	for env, value := range tests {
+		t.Run(env, func(t *testing.T) {
+			setEnv(t)
+			t.Setenv(env, value)
+
+			_, err := config.Load()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadMetricsAddress(t *testing.T) {
+	setEnv(t)
+	t.Setenv("METRICS_ENABLED", "true")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:9090", cfg.MetricsAddress)
+
+	t.Setenv("METRICS_ADDRESS", ":8080")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "metrics_address")
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Setenv("CONFIG_PATH", filepath.Join(t.TempDir(), "missing.yml"))
+
+	_, err := config.Load()
+	assert.Error(t, err)
+}
+
+func TestLoadRefusesDefaultTokenInProd(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "")
+	t.Setenv("ENV", "prod")
+	t.Setenv("AUTH_TOKEN", config.DefaultAuthToken)
+
+	_, err := config.Load()
+	assert.ErrorContains(t, err, "auth_token")
+
+	t.Setenv("ENV", "dev")
+	_, err = config.Load()
+	assert.NoError(t, err)
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "db_password")
+	tokenFile := filepath.Join(dir, "auth_token")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("db-secret\n"), 0o600))
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token-from-file"), 0o600))
+
+	setEnv(t)
+	t.Setenv("DB_PASSWORD", "plain")
+	t.Setenv("DB_PASSWORD_FILE", passwordFile)
+	t.Setenv("AUTH_TOKEN_FILE", tokenFile)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "db-secret", cfg.Password_db)
+	assert.Equal(t, "token-from-file", cfg.AuthToken)
+
+	t.Setenv("AUTH_TOKEN_FILE", filepath.Join(dir, "missing"))
+	_, err = config.Load()
+	assert.Error(t, err)
+}
+
+func TestConfigRedactsSecrets(t *testing.T) {
+	cfg := config.Config{
+		Env:         "dev",
+		Password_db: "db-secret",
+		Admin:       config.Admin{AuthToken: "admin-secret"},
+		Auth:        config.Auth{JWTKeys: map[string]string{"k1": "jwt-secret"}},
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("cfg", "data", cfg, "ptr", &cfg)
+
+	for _, out := range []string{fmt.Sprint(cfg), fmt.Sprintf("%+v", &cfg), fmt.Sprintf("%#v", cfg), buf.String()} {
+		assert.NotContains(t, out, "db-secret")
+		assert.NotContains(t, out, "admin-secret")
+		assert.NotContains(t, out, "jwt-secret")
+		assert.Contains(t, out, "REDACTED")
+	}
+
+	assert.Equal(t, "db-secret", cfg.Password_db, "redaction must not modify the config")
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+	setEnv(t)
+	t.Setenv("JWT_KEYS", "old:"+testJWTSecret+",new:"+testJWTSecret)
+	t.Setenv("JWT_ACTIVE_KEY", "new")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Len(t, cfg.JWTKeys, 2)
+	assert.Equal(t, "new", cfg.JWTActiveKey)
+
+	t.Setenv("JWT_ACTIVE_KEY", "missing")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "jwt_active_key")
+
+	t.Setenv("JWT_KEYS", "short:secret")
+	t.Setenv("JWT_ACTIVE_KEY", "")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "32 bytes")
+}
+
+func TestLoadJWTKeysFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt_keys")
+	require.NoError(t, os.WriteFile(path, []byte("2024:"+testJWTSecret+"\n2025:"+testJWTSecret+"\n"), 0o600))
+
+	setEnv(t)
+	t.Setenv("JWT_KEYS_FILE", path)
+	t.Setenv("JWT_ACTIVE_KEY", "2025")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"2024": testJWTSecret, "2025": testJWTSecret}, cfg.JWTKeys)
+}
+
+func TestLoadEphemeralJWTKeyInDev(t *testing.T) {
+	setEnv(t)
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("ENV", "dev")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, config.EphemeralJWTKeyID, cfg.JWTActiveKey)
+
+	t.Setenv("ENV", "prod")
+	_, err = config.Load()
+	assert.ErrorContains(t, err, "jwt_keys")
+}
+
+func TestParseLimit(t *testing.T) {
+	limit, err := config.ParseLimit("30/1m")
+	require.NoError(t, err)
+	assert.Equal(t, config.Limit{Requests: 30, Period: time.Minute}, limit)
+
+	limit, err = config.ParseLimit("5/s")
+	require.NoError(t, err)
+	assert.Equal(t, config.Limit{Requests: 5, Period: time.Second}, limit)
+
+	for _, s := range []string{"", "30", "0/1m", "-1/1m", "30/", "30/forever"} {
+		_, err := config.ParseLimit(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestRouteLimit(t *testing.T) {
+	setEnv(t)
+	t.Setenv("RATE_LIMIT_DEFAULT", "120/1m")
+	t.Setenv("RATE_LIMIT_ROUTES", "/api/movies/byTitleFragment:10/1s")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	limit, ok := cfg.RouteLimit("/api/movies/byTitleFragment")
+	assert.True(t, ok)
+	assert.Equal(t, config.Limit{Requests: 10, Period: time.Second}, limit)
+
+	limit, ok = cfg.RouteLimit("/api/actors")
+	assert.True(t, ok)
+	assert.Equal(t, config.Limit{Requests: 120, Period: time.Minute}, limit)
+
+	cfg.RateLimitDefault = ""
+	_, ok = cfg.RouteLimit("/api/actors")
+	assert.False(t, ok)
+}
+
+func TestLoadCORS(t *testing.T) {
+	setEnv(t)
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://movies.example.com,http://localhost:3000")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"https://movies.example.com", "http://localhost:3000"}, cfg.CORSAllowedOrigins)
+	assert.Contains(t, cfg.CORSAllowedHeaders, "X-API-Key")
+	assert.Equal(t, 10*time.Minute, cfg.CORSMaxAge)
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	_, err = config.Load()
+	assert.Error(t, err, "credentials can't be allowed for any origin")
+}
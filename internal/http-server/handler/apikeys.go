@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/models/apikey"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+)
+
+// NewAPIKeyRequest is the request body of API key creation.
+type NewAPIKeyRequest struct {
+	Name   string         `json:"name"`
+	Scopes []apikey.Scope `json:"scopes"`
+}
+
+// NewAPIKeyResponse is returned on API key creation. The key itself is shown only once.
+type NewAPIKeyResponse struct {
+	Key    string        `json:"key"`
+	APIKey apikey.APIKey `json:"api_key"`
+}
+
+// @Summary Выдача API-ключа
+// @Description Создание API-ключа с указанными правами: catalog:read, movies:write, actors:write. Ключ возвращается только в этом ответе
+// @Tags APIKeys
+// @ID createAPIKey
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param apikey body NewAPIKeyRequest true "Название и права ключа"
+// @Success 201 {object} NewAPIKeyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/apikeys [post]
+func CreateAPIKeyHandler(a *auth.Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req NewAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := apikey.New(req.Name, req.Scopes); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		principal, _ := auth.PrincipalFromContext(r.Context())
+		key, k, err := a.CreateAPIKey(r.Context(), req.Name, req.Scopes, principal.UserID)
+		if err != nil {
+			internalError(w, r, "Ошибка при создании ключа", err)
+			return
+		}
+
+		writeJSON(w, r, http.StatusCreated, NewAPIKeyResponse{Key: key, APIKey: k})
+	}
+}
+
+// @Summary Список API-ключей
+// @Description Получение списка API-ключей с временем последнего использования
+// @Tags APIKeys
+// @ID getAPIKeys
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} apikey.APIKey
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/apikeys [get]
+func APIKeysHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keys, err := s.GetAPIKeys(r.Context())
+		if err != nil {
+			internalError(w, r, "Ошибка при получении списка ключей", err)
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, keys)
+	}
+}
+
+// @Summary Отзыв API-ключа
+// @Description Отзыв API-ключа по идентификатору
+// @Tags APIKeys
+// @ID revokeAPIKey
+// @Security ApiKeyAuth
+// @Param id path int true "ID ключа"
+// @Success 204 "API key revoked"
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/apikeys/{id} [delete]
+func RevokeAPIKeyHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keyID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID ключа", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.RevokeAPIKey(r.Context(), keyID); err != nil {
+			if errors.Is(err, storage.ErrAPIKeyNotFound) {
+				http.Error(w, "Ключ не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при отзыве ключа", err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
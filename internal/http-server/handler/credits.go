@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/P1coFly/vk_movies/internal/models/credit"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+)
+
+// CreditRequest is a credit in the request body of setting the credits of a movie.
+type CreditRequest struct {
+	PersonID      int64       `json:"person_id"`
+	Role          credit.Role `json:"role"`
+	CharacterName string      `json:"character_name,omitempty"`
+	BillingOrder  int         `json:"billing_order,omitempty"`
+}
+
+// @Summary Установка участников фильма
+// @Description Замена всех участников фильма: актёров, режиссёров, сценаристов и продюсеров.
+// @Description Роль: actor, director, writer, producer; имя персонажа и порядок в титрах (1 — главная роль)
+// @Description указываются только для актёров.
+// @Tags Movies
+// @ID setMovieCredits
+// @Accept json
+// @Security ApiKeyAuth
+// @Security ApiKeyHeader
+// @Param id path int true "ID фильма"
+// @Param credits body []CreditRequest true "Участники фильма"
+// @Success 204 "Credits set successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/movies/{id}/credits [put]
+func SetMovieCreditsHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		movieID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
+			return
+		}
+
+		var req []CreditRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		credits := make([]credit.Credit, len(req))
+		for i, c := range req {
+			cr, err := credit.New(c.PersonID, c.Role, c.CharacterName, c.BillingOrder)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			credits[i] = *cr
+		}
+
+		if err := s.SetMovieCredits(r.Context(), movieID, credits); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrMovieNotFound):
+				http.Error(w, "Фильм не найден", http.StatusNotFound)
+			case errors.Is(err, storage.ErrActorNotFound):
+				http.Error(w, "Участник не найден", http.StatusBadRequest)
+			default:
+				internalError(w, r, "Ошибка при сохранении участников фильма", err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/P1coFly/vk_movies/internal/models/genre"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+)
+
+// GenreRequest is the request body of genre creation and renaming.
+type GenreRequest struct {
+	Name string `json:"name"`
+}
+
+// @Summary Список жанров
+// @Description Получение списка жанров по алфавиту
+// @Tags Genres
+// @ID getGenres
+// @Produce json
+// @Success 200 {array} genre.Genre
+// @Failure 500 {object} ErrorResponse
+// @Router /api/genres [get]
+func GenresHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		genres, err := s.GetGenres(r.Context())
+		if err != nil {
+			internalError(w, r, "Ошибка при получении списка жанров", err)
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, genres)
+	}
+}
+
+// @Summary Получение жанра
+// @Description Получение жанра по ID
+// @Tags Genres
+// @ID getGenre
+// @Produce json
+// @Param id path int true "ID жанра"
+// @Success 200 {object} genre.Genre
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/genres/{id} [get]
+func GetGenreHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		genreID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID жанра", http.StatusBadRequest)
+			return
+		}
+
+		g, err := s.GetGenreByID(r.Context(), genreID)
+		if err != nil {
+			if errors.Is(err, storage.ErrGenreNotFound) {
+				http.Error(w, "Жанр не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при получении жанра", err)
+			}
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, g)
+	}
+}
+
+// @Summary Создание жанра
+// @Description Создание жанра, названия жанров уникальны без учета регистра
+// @Tags Genres
+// @ID createGenre
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param genre body GenreRequest true "Жанр"
+// @Success 201 {object} genre.Genre
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/genres [post]
+func CreateGenreHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req GenreRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		g, err := genre.New(req.Name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		g.Id, err = s.SaveGenre(r.Context(), *g)
+		if err != nil {
+			if errors.Is(err, storage.ErrGenreExists) {
+				http.Error(w, "Жанр с таким названием уже существует", http.StatusConflict)
+			} else {
+				internalError(w, r, "Ошибка при сохранении жанра", err)
+			}
+			return
+		}
+
+		writeJSON(w, r, http.StatusCreated, g)
+	}
+}
+
+// @Summary Переименование жанра
+// @Description Изменение названия жанра
+// @Tags Genres
+// @ID updateGenre
+// @Accept json
+// @Security ApiKeyAuth
+// @Param id path int true "ID жанра"
+// @Param genre body GenreRequest true "Новое название жанра"
+// @Success 200 "Genre updated successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/genres/{id} [patch]
+func UpdateGenreHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		genreID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID жанра", http.StatusBadRequest)
+			return
+		}
+
+		var req GenreRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		g, err := genre.New(req.Name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := s.UpdateGenre(r.Context(), genreID, g.Name); err != nil {
+			switch {
+			case errors.Is(err, storage.ErrGenreNotFound):
+				http.Error(w, "Жанр не найден", http.StatusNotFound)
+			case errors.Is(err, storage.ErrGenreExists):
+				http.Error(w, "Жанр с таким названием уже существует", http.StatusConflict)
+			default:
+				internalError(w, r, "Ошибка при обновлении жанра", err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// @Summary Удаление жанра
+// @Description Удаление жанра, фильмы его теряют
+// @Tags Genres
+// @ID deleteGenre
+// @Security ApiKeyAuth
+// @Param id path int true "ID жанра"
+// @Success 204 "Genre deleted successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/genres/{id} [delete]
+func DeleteGenreHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		genreID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID жанра", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.DeleteGenreByID(r.Context(), genreID); err != nil {
+			if errors.Is(err, storage.ErrGenreNotFound) {
+				http.Error(w, "Жанр не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при удалении жанра", err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -1,20 +1,30 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
-	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/P1coFly/vk_movies/internal/cache"
+	"github.com/P1coFly/vk_movies/internal/logger"
 	"github.com/P1coFly/vk_movies/internal/models/actor"
 	"github.com/P1coFly/vk_movies/internal/models/movie"
+	"github.com/P1coFly/vk_movies/internal/models/suggestion"
 	"github.com/P1coFly/vk_movies/internal/storage"
 	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("github.com/P1coFly/vk_movies/internal/http-server/handler")
+
 // @Summary Получение списка актеров
 // @Description Получение списка всех актеров из базы данных
 // @Tags Actors
@@ -25,327 +35,625 @@ import (
 // @Router /api/actors [get]
 func ActorsHandler(s *postgresql.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		actors, err := s.GetActors(r.Context())
+		if err != nil {
+			internalError(w, r, "Ошибка при получении списка актеров", err)
+			return
+		}
+
+		// Отправляем ответ в формате JSON
+		w.Header().Set("Content-Type", "application/json")
+		if err := encodeJSON(r.Context(), w, actors); err != nil {
+			internalError(w, r, "Ошибка при кодировании ответа в JSON", err)
 			return
 		}
+	}
+}
 
-		actors, err := s.GetActors()
+// @Summary Получение актера
+// @Description Получение актера по ID. Credits — участие человека в фильмах с ролями, начиная с последних фильмов
+// @Tags Actors
+// @ID getActor
+// @Produce json
+// @Param id path int true "ID актера"
+// @Success 200 {object} actor.Actor
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/actors/{id} [get]
+func GetActorHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		actorID, err := pathID(r)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Ошибка при получении списка актеров: %s", err), http.StatusInternalServerError)
+			http.Error(w, "Неверный формат ID актера", http.StatusBadRequest)
 			return
 		}
 
-		// Отправляем ответ в формате JSON
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(actors); err != nil {
-			http.Error(w, fmt.Sprintf("Ошибка при кодировании ответа в JSON: %s", err), http.StatusInternalServerError)
+		a, err := s.GetActorByID(r.Context(), actorID)
+		if err != nil {
+			if errors.Is(err, storage.ErrActorNotFound) {
+				http.Error(w, "Актер не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при получении актера", err)
+			}
 			return
 		}
 
-		// Успешный ответ
-		w.WriteHeader(http.StatusOK)
+		writeJSON(w, r, http.StatusOK, a)
 	}
 }
 
-// @Summary Управление актерами
-// @Description Создание, обновление и удаление актеров
-// @Tags Actor
-// @ID manageActors
-// @Accept json
+// @Summary Фильмы актера
+// @Description Фильмография актера с именами персонажей и порядком в титрах, начиная с последних фильмов
+// @Tags Actors
+// @ID getActorMovies
 // @Produce json
-// @Security ApiKeyAuth
-// @Router /api/actor [post]
-// @Router /api/actor [patch]
-// @Router /api/actor [delete]
-func ActorHandler(s *postgresql.Storage, cfg *config.Config) http.HandlerFunc {
-	return AuthenticatedHandler(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost:
-			saveActorHandler(s, w, r)
-		case http.MethodPatch:
-			updateActorHandler(s, w, r)
-		case http.MethodDelete:
-			deleteActorHandler(s, w, r)
-		default:
-			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		}
-	}, cfg)
+// @Param id path int true "ID актера"
+// @Success 200 {array} movie.Appearance
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/actors/{id}/movies [get]
+func ActorMoviesHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		actorID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID актера", http.StatusBadRequest)
+			return
+		}
+
+		movies, err := s.GetMoviesByActorID(r.Context(), actorID)
+		if err != nil {
+			if errors.Is(err, storage.ErrActorNotFound) {
+				http.Error(w, "Актер не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при получении фильмов актера", err)
+			}
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, movies)
+	}
 }
 
 // @Summary Создание актера
 // @Description Создание нового актера в базе данных
-// @Tags Actor
+// @Tags Actors
 // @ID createActor
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
+// @Security ApiKeyHeader
+// @Param actor body actor.Actor true "Актер"
 // @Success 201 "Actor created successfully"
 // @Failure 400 {object} ErrorResponse
-// @Router /api/actor [post]
-func saveActorHandler(s *postgresql.Storage, w http.ResponseWriter, r *http.Request) {
-	const op = "saveActorHandler"
+// @Failure 403 {object} ErrorResponse
+// @Router /api/actors [post]
+func CreateActorHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "createActorHandler"
 
-	if r.Method != http.MethodPost {
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		return
-	}
+		// Чтение данных из тела запроса
+		var actor actor.Actor
+		if err := json.NewDecoder(r.Body).Decode(&actor); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
 
-	// Чтение данных из тела запроса
-	var actor actor.Actor
-	if err := json.NewDecoder(r.Body).Decode(&actor); err != nil {
-		http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
-		return
-	}
+		// Сохранение актера в базе данных
+		if err := s.SaveActor(r.Context(), actor.Name, actor.Sex, actor.Birthday); err != nil {
+			internalError(w, r, "Ошибка при сохранении актера", err)
+			return
+		}
 
-	// Сохранение актера в базе данных
-	if err := s.SaveActor(actor.Name, actor.Sex, actor.Birthday); err != nil {
-		http.Error(w, fmt.Sprintf("Ошибка при сохранении актера: %s", err), http.StatusInternalServerError)
-		return
+		// Успешный ответ
+		w.WriteHeader(http.StatusCreated)
 	}
-
-	// Успешный ответ
-	w.WriteHeader(http.StatusCreated)
 }
 
 // @Summary Обновление актера
 // @Description Обновление существующего актера в базе данных
-// @Tags Actor
+// @Tags Actors
 // @ID updateActor
 // @Accept json
 // @Produce json
-// @Param actorID query string true "ID актера"
+// @Security ApiKeyAuth
+// @Security ApiKeyHeader
+// @Param id path int true "ID актера"
+// @Param actor body actor.Actor true "Новые данные актера"
 // @Success 200 "Actor updated successfully"
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
-// @Router /api/actor [patch]
-func updateActorHandler(s *postgresql.Storage, w http.ResponseWriter, r *http.Request) {
-	const op = "updateActorHandler"
-
-	if r.Method != http.MethodPatch {
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		return
-	}
+// @Router /api/actors/{id} [patch]
+func UpdateActorHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "updateActorHandler"
 
-	// Парсинг ID актера из URL
-	actorIDStr := r.URL.Query().Get("actorID")
-	actorID, err := strconv.ParseInt(actorIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Неверный формат ID актера", http.StatusBadRequest)
-		return
-	}
+		// Парсинг ID актера из пути
+		actorID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID актера", http.StatusBadRequest)
+			return
+		}
 
-	// Чтение данных из тела запроса
-	var actor actor.Actor
-	if err := json.NewDecoder(r.Body).Decode(&actor); err != nil {
-		http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
-		return
-	}
+		// Чтение данных из тела запроса
+		var actor actor.Actor
+		if err := json.NewDecoder(r.Body).Decode(&actor); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
 
-	// Обновление актера в базе данных
-	if err := s.UpdateActor(actorID, actor.Name, actor.Sex, actor.Birthday); err != nil {
-		if errors.Is(err, storage.ErrActorNotFound) {
-			http.Error(w, "Актер не найден", http.StatusNotFound)
-		} else {
-			http.Error(w, fmt.Sprintf("Ошибка при обновлении актера: %s", err), http.StatusInternalServerError)
+		// Обновление актера в базе данных
+		if err := s.UpdateActor(r.Context(), actorID, actor.Name, actor.Sex, actor.Birthday); err != nil {
+			if errors.Is(err, storage.ErrActorNotFound) {
+				http.Error(w, "Актер не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при обновлении актера", err)
+			}
+			return
 		}
-		return
-	}
 
-	// Успешный ответ
-	w.WriteHeader(http.StatusOK)
+		// Успешный ответ
+		w.WriteHeader(http.StatusOK)
+	}
 }
 
 // @Summary Удаление актера
 // @Description Удаление актера из базы данных
-// @Tags Actor
+// @Tags Actors
 // @ID deleteActor
-// @Param actorID query string true "ID актера"
+// @Security ApiKeyAuth
+// @Security ApiKeyHeader
+// @Param id path int true "ID актера"
 // @Success 200 "Actor deleted successfully"
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
-// @Router /api/actor [delete]
-func deleteActorHandler(s *postgresql.Storage, w http.ResponseWriter, r *http.Request) {
-	const op = "deleteActorHandler"
+// @Router /api/actors/{id} [delete]
+func DeleteActorHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "deleteActorHandler"
+
+		// Парсинг ID актера из пути
+		actorID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID актера", http.StatusBadRequest)
+			return
+		}
+
+		// Удаление актера из базы данных
+		if err := s.DeleteActorByID(r.Context(), actorID); err != nil {
+			if errors.Is(err, storage.ErrActorNotFound) {
+				http.Error(w, "Актер не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при удалении актера", err)
+			}
+			return
+		}
+
+		// Успешный ответ
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// Limits of the number of movies returned by the movie list.
+const (
+	defaultMoviesLimit = 50
+	maxMoviesLimit     = 100
+)
+
+// @Summary Получение списка фильмов
+// @Description Список фильмов, удовлетворяющих всем заданным фильтрам, по умолчанию по рейтингу по убыванию.
+// @Description Общее число подходящих фильмов возвращается в заголовке X-Total-Count.
+// @Tags Movies
+// @ID getMovies
+// @Produce json
+// @Param title query string false "Фрагмент названия"
+// @Param actor query string false "Фрагмент имени одного из актеров"
+// @Param director query string false "Фрагмент имени одного из режиссёров"
+// @Param date_from query string false "Дата выхода не раньше, YYYY-MM-DD"
+// @Param date_to query string false "Дата выхода не позже, YYYY-MM-DD"
+// @Param rating_min query number false "Рейтинг не ниже"
+// @Param rating_max query number false "Рейтинг не выше"
+// @Param genre_ids query string false "ID жанров через запятую, фильм должен иметь все"
+// @Param sort query string false "Поле сортировки: title, rating, date_of_issue"
+// @Param order query string false "Порядок сортировки: asc, desc"
+// @Param limit query int false "Число фильмов, от 1 до 100" default(50)
+// @Param offset query int false "Сколько фильмов пропустить" default(0)
+// @Success 200 {array} movie.Movie
+// @Header 200 {integer} X-Total-Count "Число фильмов, подходящих под фильтры"
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/movies [get]
+func MoviesHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f, err := movieFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		movies, total, err := s.FindMovies(r.Context(), f)
+		if err != nil {
+			internalError(w, r, "Ошибка при получении списка фильмов", err)
+			return
+		}
+		if movies == nil {
+			movies = []movie.Movie{}
+		}
 
-	if r.Method != http.MethodDelete {
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		return
+		w.Header().Set("X-Total-Count", strconv.Itoa(total))
+		writeJSON(w, r, http.StatusOK, movies)
 	}
+}
 
-	// Парсинг ID актера из URL
-	actorIDStr := r.URL.Query().Get("actorID")
-	actorID, err := strconv.ParseInt(actorIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Неверный формат ID актера", http.StatusBadRequest)
-		return
+// movieFilter reads the filters, sorting and page of the movie list from the query string.
+func movieFilter(query url.Values) (movie.Filter, error) {
+	f := movie.Filter{
+		Title:    strings.TrimSpace(query.Get("title")),
+		Actor:    strings.TrimSpace(query.Get("actor")),
+		Director: strings.TrimSpace(query.Get("director")),
+		DateFrom: query.Get("date_from"),
+		DateTo:   query.Get("date_to"),
+		Sort:     query.Get("sort"),
+		Limit:    defaultMoviesLimit,
 	}
 
-	// Удаление актера из базы данных
-	if err := s.DeleteActorByID(actorID); err != nil {
-		if errors.Is(err, storage.ErrActorNotFound) {
-			http.Error(w, "Актер не найден", http.StatusNotFound)
-		} else {
-			http.Error(w, fmt.Sprintf("Ошибка при удалении актера: %s", err), http.StatusInternalServerError)
+	if f.Sort == "" {
+		f.Sort = movie.SortByRating
+	}
+	if f.Sort != movie.SortByTitle && f.Sort != movie.SortByRating && f.Sort != movie.SortByDateOfIssue {
+		return movie.Filter{}, errors.New("Неверные параметры сортировки")
+	}
+	switch strings.ToUpper(query.Get("order")) {
+	case "", "DESC":
+		f.Desc = true
+	case "ASC":
+	default:
+		return movie.Filter{}, errors.New("Неверные параметры сортировки")
+	}
+
+	for _, date := range []string{f.DateFrom, f.DateTo} {
+		if _, err := time.Parse(time.DateOnly, date); date != "" && err != nil {
+			return movie.Filter{}, errors.New("Даты date_from и date_to должны быть в формате YYYY-MM-DD")
+		}
+	}
+
+	var err error
+	if f.RatingMin, err = ratingParam(query, "rating_min"); err != nil {
+		return movie.Filter{}, err
+	}
+	if f.RatingMax, err = ratingParam(query, "rating_max"); err != nil {
+		return movie.Filter{}, err
+	}
+	if f.GenreIDs, err = parseIDs(query.Get("genre_ids")); err != nil {
+		return movie.Filter{}, fmt.Errorf("genre_ids: %w", err)
+	}
+
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxMoviesLimit {
+			return movie.Filter{}, fmt.Errorf("limit должен быть числом от 1 до %d", maxMoviesLimit)
+		}
+		f.Limit = n
+	}
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return movie.Filter{}, errors.New("offset должен быть неотрицательным числом")
 		}
-		return
+		f.Offset = n
 	}
 
-	// Успешный ответ
-	w.WriteHeader(http.StatusOK)
+	return f, nil
 }
 
-// @Summary Управление фильмами
-// @Description Создание, обновление и удаление фильмов
-// @Tags Movie
-// @ID manageMovies
-// @Accept json
+// ratingParam reads an optional rating bound, nil if it is not set.
+func ratingParam(query url.Values, name string) (*float64, error) {
+	v := query.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	rating, err := strconv.ParseFloat(v, 64)
+	if err != nil || rating < 0 || rating > 10 {
+		return nil, fmt.Errorf("%s должен быть числом от 0 до 10", name)
+	}
+	return &rating, nil
+}
+
+// @Summary Получение фильма
+// @Description Получение фильма по ID вместе с участниками: актёрами, режиссёрами, сценаристами и продюсерами
+// @Tags Movies
+// @ID getMovie
 // @Produce json
-// @Security ApiKeyAuth
-// @Router /api/movie [post]
-// @Router /api/movie [patch]
-// @Router /api/movie [delete]
-func MovieHandler(s *postgresql.Storage, cfg *config.Config) http.HandlerFunc {
-	return AuthenticatedHandler(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost:
-			saveMovieHandler(s, w, r)
-		case http.MethodPatch:
-			updateMovieHandler(s, w, r)
-		case http.MethodDelete:
-			deleteMovieHandler(s, w, r)
-		default:
-			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		}
-	}, cfg)
+// @Param id path int true "ID фильма"
+// @Success 200 {object} movie.Movie
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/movies/{id} [get]
+func GetMovieHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		movieID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
+			return
+		}
+
+		m, err := s.GetMovieByID(r.Context(), movieID)
+		if err != nil {
+			if errors.Is(err, storage.ErrMovieNotFound) {
+				http.Error(w, "Фильм не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при получении фильма", err)
+			}
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, m)
+	}
 }
 
 // @Summary Создание фильма
 // @Description Создание нового фильма в базе данных
-// @Tags Movie
+// @Tags Movies
 // @ID createMovie
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
+// @Security ApiKeyHeader
+// @Param movie body movie.Movie true "Фильм"
+// @Param actorIDs query string false "ID актеров через запятую"
+// @Param genreIDs query string false "ID жанров через запятую"
 // @Success 201 "Movie created successfully"
 // @Failure 400 {object} ErrorResponse
-// @Router /api/movie [post]
-func saveMovieHandler(s *postgresql.Storage, w http.ResponseWriter, r *http.Request) {
-	const op = "saveMovieHandler"
+// @Failure 403 {object} ErrorResponse
+// @Router /api/movies [post]
+func CreateMovieHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "createMovieHandler"
 
-	if r.Method != http.MethodPost {
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		return
-	}
+		// Чтение данных из тела запроса
+		var m movie.Movie
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
 
-	// Чтение данных из тела запроса
-	var m movie.Movie
-	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
-		http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
-		return
-	}
+		// Чтение списка ID актеров из параметров запроса
+		actorIDs, err := readActorIDsFromRequest(r)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при чтении ID актеров: %s", err), http.StatusBadRequest)
+			return
+		}
 
-	// Чтение списка ID актеров из параметров запроса
-	actorIDs, err := readActorIDsFromRequest(r)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Ошибка при чтении ID актеров: %s", err), http.StatusBadRequest)
-		return
-	}
+		genreIDs, err := parseIDs(r.URL.Query().Get("genreIDs"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при чтении ID жанров: %s", err), http.StatusBadRequest)
+			return
+		}
 
-	// Сохранение фильма в базе данных
-	fmt.Println(actorIDs)
-	if err := s.SaveMovie(m, actorIDs); err != nil {
-		http.Error(w, fmt.Sprintf("Ошибка при сохранении фильма: %s", err), http.StatusInternalServerError)
-		return
-	}
+		// Сохранение фильма в базе данных
+		if err := s.SaveMovie(r.Context(), m, actorIDs, genreIDs); err != nil {
+			if errors.Is(err, storage.ErrGenreNotFound) {
+				http.Error(w, "Жанр не найден", http.StatusBadRequest)
+			} else {
+				internalError(w, r, "Ошибка при сохранении фильма", err)
+			}
+			return
+		}
 
-	// Успешный ответ
-	w.WriteHeader(http.StatusCreated)
+		// Успешный ответ
+		w.WriteHeader(http.StatusCreated)
+	}
 }
 
 // @Summary Обновление фильма
 // @Description Обновление существующего фильма в базе данных
-// @Tags Movie
+// @Tags Movies
 // @ID updateMovie
 // @Accept json
 // @Produce json
-// @Param movieID query string true "ID фильма"
+// @Security ApiKeyAuth
+// @Security ApiKeyHeader
+// @Param id path int true "ID фильма"
+// @Param movie body movie.Movie true "Новые данные фильма"
+// @Param genreIDs query string false "ID жанров через запятую, заменяют текущие; пустое значение убирает все жанры"
 // @Success 200 "Movie updated successfully"
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
-// @Router /api/movie [patch]
-func updateMovieHandler(s *postgresql.Storage, w http.ResponseWriter, r *http.Request) {
-	const op = "updateMovieHandler"
-
-	if r.Method != http.MethodPatch {
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		return
-	}
+// @Router /api/movies/{id} [patch]
+func UpdateMovieHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "updateMovieHandler"
 
-	// Парсинг ID фильма из параметров запроса
-	movieIDStr := r.URL.Query().Get("movieID")
-	movieID, err := strconv.ParseInt(movieIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
-		return
-	}
+		// Парсинг ID фильма из пути
+		movieID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
+			return
+		}
 
-	// Чтение данных из тела запроса
-	var m movie.Movie
-	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
-		http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
-		return
-	}
+		// Чтение данных из тела запроса
+		var m movie.Movie
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
 
-	// Обновление фильма в базе данных
-	if err := s.UpdateMovie(movieID, m.Title, m.Description, m.DateOfIssue, float64(m.Rating)); err != nil {
-		if errors.Is(err, storage.ErrMovieNotFound) {
-			http.Error(w, "Фильм не найден", http.StatusNotFound)
+		// Жанры заменяются вместе с фильмом в одной транзакции, только если параметр передан
+		if r.URL.Query().Has("genreIDs") {
+			var genreIDs []int64
+			genreIDs, err = parseIDs(r.URL.Query().Get("genreIDs"))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Ошибка при чтении ID жанров: %s", err), http.StatusBadRequest)
+				return
+			}
+			err = s.UpdateMovieWithGenres(r.Context(), movieID, m.Title, m.Description, m.DateOfIssue, m.Rating, genreIDs)
 		} else {
-			http.Error(w, fmt.Sprintf("Ошибка при обновлении фильма: %s", err), http.StatusInternalServerError)
+			err = s.UpdateMovie(r.Context(), movieID, m.Title, m.Description, m.DateOfIssue, m.Rating)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrMovieNotFound):
+				http.Error(w, "Фильм не найден", http.StatusNotFound)
+			case errors.Is(err, storage.ErrGenreNotFound):
+				http.Error(w, "Жанр не найден", http.StatusBadRequest)
+			default:
+				internalError(w, r, "Ошибка при обновлении фильма", err)
+			}
+			return
 		}
-		return
-	}
 
-	// Успешный ответ
-	w.WriteHeader(http.StatusOK)
+		// Успешный ответ
+		w.WriteHeader(http.StatusOK)
+	}
 }
 
 // @Summary Удаление фильма
 // @Description Удаление фильма из базы данных
-// @Tags Movie
+// @Tags Movies
 // @ID deleteMovie
-// @Param movieID query string true "ID фильма"
+// @Security ApiKeyAuth
+// @Security ApiKeyHeader
+// @Param id path int true "ID фильма"
 // @Success 200 "Movie deleted successfully"
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
-// @Router /api/movie [delete]
-func deleteMovieHandler(s *postgresql.Storage, w http.ResponseWriter, r *http.Request) {
-	const op = "deleteMovieHandler"
+// @Router /api/movies/{id} [delete]
+func DeleteMovieHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "deleteMovieHandler"
 
-	if r.Method != http.MethodDelete {
-		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-		return
-	}
+		// Парсинг ID фильма из пути
+		movieID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
+			return
+		}
 
-	// Парсинг ID фильма из параметров запроса
-	movieIDStr := r.URL.Query().Get("movieID")
-	movieID, err := strconv.ParseInt(movieIDStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
-		return
+		// Удаление фильма из базы данных
+		if err := s.DeleteMovieByID(r.Context(), movieID); err != nil {
+			if errors.Is(err, storage.ErrMovieNotFound) {
+				http.Error(w, "Фильм не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при удалении фильма", err)
+			}
+			return
+		}
+
+		// Успешный ответ
+		w.WriteHeader(http.StatusOK)
 	}
+}
 
-	// Удаление фильма из базы данных
-	if err := s.DeleteMovieByID(movieID); err != nil {
-		if errors.Is(err, storage.ErrMovieNotFound) {
-			http.Error(w, "Фильм не найден", http.StatusNotFound)
-		} else {
-			http.Error(w, fmt.Sprintf("Ошибка при удалении фильма: %s", err), http.StatusInternalServerError)
+// Limits of the number of movies returned by search.
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// @Summary Полнотекстовый поиск фильмов
+// @Description Поиск по названию и описанию на русском и английском, самые релевантные фильмы первыми.
+// @Description Запрос в синтаксисе веб-поиска: слова, "фразы в кавычках", OR и -исключённые слова.
+// @Description В headline и snippet найденные слова выделены тегами <b></b>, остальной текст экранирован как HTML.
+// @Tags Movies
+// @ID searchMovies
+// @Produce json
+// @Param q query string true "Поисковый запрос"
+// @Param limit query int false "Число фильмов, от 1 до 100" default(20)
+// @Success 200 {array} movie.SearchResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/movies/search [get]
+func SearchMoviesHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			http.Error(w, "Необходимо указать поисковый запрос q", http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultSearchLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxSearchLimit {
+				http.Error(w, fmt.Sprintf("limit должен быть числом от 1 до %d", maxSearchLimit), http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		results, err := s.SearchMovies(r.Context(), query, limit)
+		if err != nil {
+			internalError(w, r, "Ошибка при поиске фильмов", err)
+			return
+		}
+		if results == nil {
+			results = []movie.SearchResult{}
 		}
-		return
+
+		writeJSON(w, r, http.StatusOK, results)
 	}
+}
+
+// Limits of suggestions.
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 20
+	maxSuggestPrefix    = 100
+)
+
+// @Summary Подсказки при вводе
+// @Description Названия фильмов и имена актеров, начинающиеся с введённого текста, без учёта регистра.
+// @Description Точные совпадения и самые короткие варианты идут первыми. Ответы кэшируются на сервере,
+// @Description поэтому изменения каталога появляются в подсказках с задержкой до SEARCH_SUGGEST_CACHE_TTL.
+// @Tags Search
+// @ID suggest
+// @Produce json
+// @Param q query string true "Начало названия или имени"
+// @Param limit query int false "Число подсказок, от 1 до 20" default(10)
+// @Success 200 {array} suggestion.Suggestion
+// @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/suggest [get]
+func SuggestHandler(s *postgresql.Storage, cacheTTL time.Duration, cacheSize int) http.HandlerFunc {
+	suggestions := cache.New[string, []suggestion.Suggestion](cacheTTL, cacheSize)
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(cacheTTL.Seconds()))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.Join(strings.Fields(r.URL.Query().Get("q")), " ")
+		if prefix == "" || utf8.RuneCountInString(prefix) > maxSuggestPrefix {
+			http.Error(w, fmt.Sprintf("q должен содержать от 1 до %d символов", maxSuggestPrefix), http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultSuggestLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxSuggestLimit {
+				http.Error(w, fmt.Sprintf("limit должен быть числом от 1 до %d", maxSuggestLimit), http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		key := strconv.Itoa(limit) + ":" + strings.ToLower(prefix)
+		result, ok := suggestions.Get(key)
+		if !ok {
+			var err error
+			result, err = s.Suggest(r.Context(), prefix, limit)
+			if err != nil {
+				internalError(w, r, "Ошибка при получении подсказок", err)
+				return
+			}
+			if result == nil {
+				result = []suggestion.Suggestion{}
+			}
+			suggestions.Set(key, result)
+		}
 
-	// Успешный ответ
-	w.WriteHeader(http.StatusOK)
+		w.Header().Set("Cache-Control", cacheControl)
+		writeJSON(w, r, http.StatusOK, result)
+	}
 }
 
 // @Summary Поиск фильмов по фрагменту названия
@@ -356,17 +664,13 @@ func deleteMovieHandler(s *postgresql.Storage, w http.ResponseWriter, r *http.Re
 // @Param titleFragment query string true "Фрагмент названия фильма"
 // @Success 200 {array} movie.Movie
 // @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
-// @Router /api/moviesByTitle [get]
+// @Router /api/movies/byTitleFragment [get]
 func FindMoviesByTitleFragmentHandler(s *postgresql.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "findMoviesByTitleFragmentHandler"
 
-		if r.Method != http.MethodGet {
-			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-			return
-		}
-
 		// Получение фрагмента названия фильма из параметров запроса
 		titleFragment := r.URL.Query().Get("titleFragment")
 		if titleFragment == "" {
@@ -375,58 +679,59 @@ func FindMoviesByTitleFragmentHandler(s *postgresql.Storage) http.HandlerFunc {
 		}
 
 		// Поиск фильмов по фрагменту названия в хранилище
-		movies, err := s.FindMoviesByTitleFragment(titleFragment)
+		movies, err := s.FindMoviesByTitleFragment(r.Context(), titleFragment)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Ошибка при поиске фильмов: %s", err), http.StatusInternalServerError)
+			internalError(w, r, "Ошибка при поиске фильмов", err)
 			return
 		}
 
 		// Отправка ответа в формате JSON
 		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(movies); err != nil {
-			http.Error(w, fmt.Sprintf("Ошибка при кодировании JSON: %s", err), http.StatusInternalServerError)
+		if err := encodeJSON(r.Context(), w, movies); err != nil {
+			internalError(w, r, "Ошибка при кодировании JSON", err)
 			return
 		}
 	}
 }
 
 // @Summary Поиск фильмов по фрагменту имени актера
-// @Description Поиск фильмов по части имени актера в базе данных
+// @Description Нечёткий поиск фильмов по части имени актера: допускает опечатки, пробелы и написание
+// @Description кириллицей или латиницей ("Ди Каприо", "DiCaprio"). Каждый фильм возвращается один раз
+// @Description со списком подошедших актеров в matched_actors, фильмы самых похожих актеров идут первыми.
 // @Tags Movies
 // @ID findMoviesByActorNameFragment
 // @Produce json
 // @Param actorNameFragment query string true "Фрагмент имени актера"
-// @Success 200 {array} movie.Movie
+// @Success 200 {array} movie.ActorMatch
 // @Failure 400 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
-// @Router /api/moviesByActorName [get]
-func FindMoviesByActorNameFragmentHandler(s *postgresql.Storage) http.HandlerFunc {
+// @Router /api/movies/byActorNameFragment [get]
+func FindMoviesByActorNameFragmentHandler(s *postgresql.Storage, threshold float64) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "findMoviesByActorNameFragmentHandler"
 
-		if r.Method != http.MethodGet {
-			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
-			return
-		}
-
 		// Получение фрагмента имени актера из параметров запроса
-		actorNameFragment := r.URL.Query().Get("actorNameFragment")
+		actorNameFragment := strings.TrimSpace(r.URL.Query().Get("actorNameFragment"))
 		if actorNameFragment == "" {
 			http.Error(w, "Необходимо указать фрагмент имени актера", http.StatusBadRequest)
 			return
 		}
 
 		// Поиск фильмов по фрагменту имени актера в хранилище
-		movies, err := s.FindMoviesByActorNameFragment(actorNameFragment)
+		movies, err := s.FindMoviesByActorNameFragment(r.Context(), actorNameFragment, threshold)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Ошибка при поиске фильмов: %s", err), http.StatusInternalServerError)
+			internalError(w, r, "Ошибка при поиске фильмов", err)
 			return
 		}
+		if movies == nil {
+			movies = []movie.ActorMatch{}
+		}
 
 		// Отправка ответа в формате JSON
 		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(movies); err != nil {
-			http.Error(w, fmt.Sprintf("Ошибка при кодировании JSON: %s", err), http.StatusInternalServerError)
+		if err := encodeJSON(r.Context(), w, movies); err != nil {
+			internalError(w, r, "Ошибка при кодировании JSON", err)
 			return
 		}
 	}
@@ -437,6 +742,43 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// internalError logs the error with the request logger and answers 500.
+func internalError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	logger.FromContext(r.Context()).Error(msg, "error", err)
+	http.Error(w, fmt.Sprintf("%s: %s", msg, err), http.StatusInternalServerError)
+}
+
+// encodeJSON writes v as JSON in a span of its own, so that slow encoding of a large
+// result is told apart from the query in traces.
+func encodeJSON(ctx context.Context, w io.Writer, v any) error {
+	_, span := tracer.Start(ctx, "json.Encode")
+	defer span.End()
+
+	return json.NewEncoder(w).Encode(v)
+}
+
+// pathID reads the {id} path parameter of the route.
+func pathID(r *http.Request) (int64, error) {
+	return strconv.ParseInt(r.PathValue("id"), 10, 64)
+}
+
+// parseIDs reads comma separated ids, nil if the list is empty.
+func parseIDs(s string) ([]int64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	ids := make([]int64, len(parts))
+	for i, part := range parts {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("неверный формат ID: %s", part)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
 // Вспомогательная функция для чтения списка ID актеров из параметров запроса
 func readActorIDsFromRequest(r *http.Request) ([]int, error) {
 	actorIDsStr := r.URL.Query().Get("actorIDs")
@@ -454,18 +796,3 @@ func readActorIDsFromRequest(r *http.Request) ([]int, error) {
 	}
 	return actorIDs, nil
 }
-
-// AuthenticatedHandler is a wrapper function to authenticate requests before passing them to the actual handler.
-func AuthenticatedHandler(next http.HandlerFunc, cfg *config.Config) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		authToken := r.Header.Get("Authorization")
-		expectedToken := cfg.AuthToken
-		if authToken != expectedToken {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-
-		// Если аутентификация прошла успешно, передаем запрос дальше.
-		next.ServeHTTP(w, r)
-	}
-}
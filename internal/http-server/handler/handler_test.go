@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/models/movie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMovieFilter(t *testing.T) {
+	query, _ := url.ParseQuery("title=+Матрица+&actor=Киану&director=Вачовски&date_from=1999-01-01&rating_min=7.5&genre_ids=3,1&sort=date_of_issue&order=asc&limit=10&offset=20")
+
+	f, err := movieFilter(query)
+	require.NoError(t, err)
+
+	ratingMin := 7.5
+	assert.Equal(t, movie.Filter{
+		Title:     "Матрица",
+		Actor:     "Киану",
+		Director:  "Вачовски",
+		DateFrom:  "1999-01-01",
+		RatingMin: &ratingMin,
+		GenreIDs:  []int64{3, 1},
+		Sort:      movie.SortByDateOfIssue,
+		Limit:     10,
+		Offset:    20,
+	}, f)
+}
+
+func TestMovieFilterDefaults(t *testing.T) {
+	f, err := movieFilter(url.Values{})
+	require.NoError(t, err)
+
+	assert.Equal(t, movie.SortByRating, f.Sort)
+	assert.True(t, f.Desc)
+	assert.Equal(t, defaultMoviesLimit, f.Limit)
+}
+
+func TestMovieFilterInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"sort=description",
+		"order=random",
+		"date_from=01.01.1999",
+		"rating_max=11",
+		"rating_min=high",
+		"limit=0",
+		"limit=1000",
+		"offset=-1",
+		"genre_ids=1,drama",
+		"genre_ids=0",
+	} {
+		query, _ := url.ParseQuery(raw)
+		_, err := movieFilter(query)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestDecodeReview(t *testing.T) {
+	decode := func(body string) (int, bool) {
+		w := httptest.NewRecorder()
+		rv, ok := decodeReview(w, httptest.NewRequest(http.MethodPost, "/api/movies/1/reviews", strings.NewReader(body)))
+		if ok {
+			return rv.Score, true
+		}
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		return 0, false
+	}
+
+	score, ok := decode(`{"score": 0, "text": "Не понравилось"}`)
+	assert.True(t, ok, "0 is a valid score")
+	assert.Equal(t, 0, score)
+
+	_, ok = decode(`{"text": "Без оценки"}`)
+	assert.False(t, ok, "the score is required")
+	_, ok = decode(`{"score": 11}`)
+	assert.False(t, ok)
+}
@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/models/review"
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+)
+
+// ReviewRequest is the request body of review creation and editing. The score is
+// required: 0 is a valid score, so a missing one can't be told apart from it otherwise.
+type ReviewRequest struct {
+	Score *int   `json:"score"`
+	Text  string `json:"text"`
+}
+
+// Limits of the number of reviews returned by the review list.
+const (
+	defaultReviewsLimit = 20
+	maxReviewsLimit     = 100
+)
+
+// @Summary Отзывы о фильме
+// @Description Получение отзывов пользователей о фильме, начиная с новых
+// @Tags Reviews
+// @ID getMovieReviews
+// @Produce json
+// @Param id path int true "ID фильма"
+// @Param limit query int false "Число отзывов, от 1 до 100" default(20)
+// @Param offset query int false "Сколько отзывов пропустить" default(0)
+// @Success 200 {array} review.Review
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/movies/{id}/reviews [get]
+func MovieReviewsHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		movieID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
+			return
+		}
+
+		limit, offset := defaultReviewsLimit, 0
+		if v := r.URL.Query().Get("limit"); v != "" {
+			if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxReviewsLimit {
+				http.Error(w, fmt.Sprintf("limit должен быть числом от 1 до %d", maxReviewsLimit), http.StatusBadRequest)
+				return
+			}
+		}
+		if v := r.URL.Query().Get("offset"); v != "" {
+			if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+				http.Error(w, "offset должен быть неотрицательным числом", http.StatusBadRequest)
+				return
+			}
+		}
+
+		reviews, err := s.GetMovieReviews(r.Context(), movieID, limit, offset)
+		if err != nil {
+			if errors.Is(err, storage.ErrMovieNotFound) {
+				http.Error(w, "Фильм не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при получении отзывов", err)
+			}
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, reviews)
+	}
+}
+
+// @Summary Создание отзыва
+// @Description Отзыв текущего пользователя о фильме: оценка от 0 до 10 и текст. Пользователь может оставить один отзыв о фильме
+// @Tags Reviews
+// @ID createReview
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID фильма"
+// @Param review body ReviewRequest true "Оценка и текст"
+// @Success 201 {object} review.Review
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/movies/{id}/reviews [post]
+func CreateReviewHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := reviewer(w, r)
+		if !ok {
+			return
+		}
+
+		movieID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID фильма", http.StatusBadRequest)
+			return
+		}
+
+		rv, ok := decodeReview(w, r)
+		if !ok {
+			return
+		}
+		rv.MovieID = movieID
+		rv.UserID = principal.UserID
+
+		saved, err := s.SaveReview(r.Context(), *rv)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrMovieNotFound):
+				http.Error(w, "Фильм не найден", http.StatusNotFound)
+			case errors.Is(err, storage.ErrReviewExists):
+				http.Error(w, "Отзыв о фильме уже оставлен", http.StatusConflict)
+			default:
+				internalError(w, r, "Ошибка при сохранении отзыва", err)
+			}
+			return
+		}
+
+		writeJSON(w, r, http.StatusCreated, saved)
+	}
+}
+
+// @Summary Изменение отзыва
+// @Description Изменение оценки и текста своего отзыва
+// @Tags Reviews
+// @ID updateReview
+// @Accept json
+// @Security ApiKeyAuth
+// @Param id path int true "ID отзыва"
+// @Param review body ReviewRequest true "Новые оценка и текст"
+// @Success 200 "Review updated successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/reviews/{id} [patch]
+func UpdateReviewHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := reviewer(w, r)
+		if !ok {
+			return
+		}
+
+		reviewID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID отзыва", http.StatusBadRequest)
+			return
+		}
+
+		rv, ok := decodeReview(w, r)
+		if !ok {
+			return
+		}
+
+		if !reviewOwner(s, w, r, reviewID, principal, false) {
+			return
+		}
+
+		if err := s.UpdateReview(r.Context(), reviewID, rv.Score, rv.Text); err != nil {
+			if errors.Is(err, storage.ErrReviewNotFound) {
+				http.Error(w, "Отзыв не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при обновлении отзыва", err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// @Summary Удаление отзыва
+// @Description Удаление своего отзыва; администратор может удалить любой отзыв
+// @Tags Reviews
+// @ID deleteReview
+// @Security ApiKeyAuth
+// @Param id path int true "ID отзыва"
+// @Success 204 "Review deleted successfully"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/reviews/{id} [delete]
+func DeleteReviewHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := reviewer(w, r)
+		if !ok {
+			return
+		}
+
+		reviewID, err := pathID(r)
+		if err != nil {
+			http.Error(w, "Неверный формат ID отзыва", http.StatusBadRequest)
+			return
+		}
+
+		if !reviewOwner(s, w, r, reviewID, principal, true) {
+			return
+		}
+
+		if err := s.DeleteReviewByID(r.Context(), reviewID); err != nil {
+			if errors.Is(err, storage.ErrReviewNotFound) {
+				http.Error(w, "Отзыв не найден", http.StatusNotFound)
+			} else {
+				internalError(w, r, "Ошибка при удалении отзыва", err)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// reviewer returns the principal if it is a user account, service clients can't write reviews.
+func reviewer(w http.ResponseWriter, r *http.Request) (auth.Principal, bool) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	if principal.UserID == 0 {
+		http.Error(w, "Доступно только для учётных записей пользователей", http.StatusForbidden)
+		return principal, false
+	}
+	return principal, true
+}
+
+// reviewOwner checks that the review belongs to the principal, with adminAllowed admins pass as well.
+func reviewOwner(s *postgresql.Storage, w http.ResponseWriter, r *http.Request, reviewID int64, principal auth.Principal, adminAllowed bool) bool {
+	rv, err := s.GetReviewByID(r.Context(), reviewID)
+	if err != nil {
+		if errors.Is(err, storage.ErrReviewNotFound) {
+			http.Error(w, "Отзыв не найден", http.StatusNotFound)
+		} else {
+			internalError(w, r, "Ошибка при получении отзыва", err)
+		}
+		return false
+	}
+	if rv.UserID != principal.UserID && !(adminAllowed && principal.Role == user.RoleAdmin) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func decodeReview(w http.ResponseWriter, r *http.Request) (*review.Review, bool) {
+	var req ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+		return nil, false
+	}
+
+	if req.Score == nil {
+		http.Error(w, "Не указана оценка", http.StatusBadRequest)
+		return nil, false
+	}
+
+	rv, err := review.New(*req.Score, req.Text)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return rv, true
+}
@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/http-server/middleware"
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+)
+
+// Credentials is the request body of registration and login.
+type Credentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// NewUserRequest is the request body of user creation by an admin.
+type NewUserRequest struct {
+	Login    string    `json:"login"`
+	Password string    `json:"password"`
+	Role     user.Role `json:"role"`
+}
+
+// LoginResponse is returned on successful login.
+type LoginResponse struct {
+	auth.TokenPair
+	User user.User `json:"user"`
+}
+
+// RefreshRequest is the request body of token refresh and logout.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ChangePasswordRequest is the request body of a password change.
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+// @Summary Регистрация
+// @Description Регистрация нового пользователя с ролью user
+// @Tags Auth
+// @ID register
+// @Accept json
+// @Produce json
+// @Param credentials body Credentials true "Логин и пароль"
+// @Success 201 {object} user.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/register [post]
+func RegisterHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var c Credentials
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		createUser(s, w, r, c.Login, c.Password, user.RoleUser)
+	}
+}
+
+// @Summary Создание пользователя
+// @Description Создание пользователя с указанной ролью (только для администратора)
+// @Tags Auth
+// @ID createUser
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user body NewUserRequest true "Пользователь"
+// @Success 201 {object} user.User
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /api/users [post]
+func UsersHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req NewUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		createUser(s, w, r, req.Login, req.Password, req.Role)
+	}
+}
+
+func createUser(s *postgresql.Storage, w http.ResponseWriter, r *http.Request, login, password string, role user.Role) {
+	u, err := user.New(login, password, role)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	u.Id, err = s.SaveUser(r.Context(), *u)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserExists) {
+			http.Error(w, "Пользователь с таким логином уже существует", http.StatusConflict)
+		} else {
+			internalError(w, r, "Ошибка при сохранении пользователя", err)
+		}
+		return
+	}
+
+	writeJSON(w, r, http.StatusCreated, u)
+}
+
+// @Summary Вход
+// @Description Проверка логина и пароля и выдача JWT access-токена (Authorization: Bearer <token>) и refresh-токена
+// @Tags Auth
+// @ID login
+// @Accept json
+// @Produce json
+// @Param credentials body Credentials true "Логин и пароль"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/login [post]
+func LoginHandler(a *auth.Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var c Credentials
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		if err := a.CheckLockout(r); err != nil {
+			middleware.WriteLockedOut(w, err)
+			return
+		}
+
+		pair, u, err := a.Login(r.Context(), c.Login, c.Password)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				a.Failed(r, err)
+				http.Error(w, "Неверный логин или пароль", http.StatusUnauthorized)
+			} else {
+				internalError(w, r, "Ошибка при входе", err)
+			}
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, LoginResponse{TokenPair: pair, User: u})
+	}
+}
+
+// @Summary Обновление токенов
+// @Description Обмен refresh-токена на новую пару токенов. Refresh-токен одноразовый: повторное использование отзывает все токены этого входа
+// @Tags Auth
+// @ID refreshToken
+// @Accept json
+// @Produce json
+// @Param token body RefreshRequest true "Refresh-токен"
+// @Success 200 {object} auth.TokenPair
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /api/token/refresh [post]
+func RefreshHandler(a *auth.Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req RefreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+			http.Error(w, "Необходимо указать refresh_token", http.StatusBadRequest)
+			return
+		}
+
+		if err := a.CheckLockout(r); err != nil {
+			middleware.WriteLockedOut(w, err)
+			return
+		}
+
+		pair, err := a.Refresh(r.Context(), req.RefreshToken)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				a.Failed(r, err)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			} else {
+				internalError(w, r, "Ошибка при обновлении токена", err)
+			}
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, pair)
+	}
+}
+
+// @Summary Выход
+// @Description Отзыв текущего access-токена и, если передан, refresh-токена
+// @Tags Auth
+// @ID logout
+// @Accept json
+// @Security ApiKeyAuth
+// @Param token body RefreshRequest false "Refresh-токен"
+// @Success 204 "Tokens revoked"
+// @Failure 401 {object} ErrorResponse
+// @Router /api/logout [post]
+func LogoutHandler(a *auth.Authenticator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Тело запроса необязательно
+		var req RefreshRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		principal, _ := auth.PrincipalFromContext(r.Context())
+		if err := a.Logout(r.Context(), principal, req.RefreshToken); err != nil {
+			internalError(w, r, "Ошибка при выходе", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// @Summary Текущий пользователь
+// @Description Получение данных аутентифицированного пользователя
+// @Tags Auth
+// @ID me
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} user.User
+// @Failure 401 {object} ErrorResponse
+// @Router /api/me [get]
+func MeHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u, ok := currentUser(s, w, r)
+		if !ok {
+			return
+		}
+
+		writeJSON(w, r, http.StatusOK, u)
+	}
+}
+
+// @Summary Смена пароля
+// @Description Смена пароля аутентифицированного пользователя. Все refresh-токены и выданные ранее access-токены пользователя отзываются
+// @Tags Auth
+// @ID changePassword
+// @Accept json
+// @Security ApiKeyAuth
+// @Param passwords body ChangePasswordRequest true "Старый и новый пароль"
+// @Success 200 "Password changed"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /api/me/password [put]
+func ChangePasswordHandler(s *postgresql.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req ChangePasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Ошибка при декодировании JSON: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		u, ok := currentUser(s, w, r)
+		if !ok {
+			return
+		}
+		if !u.CheckPassword(req.OldPassword) {
+			http.Error(w, "Неверный пароль", http.StatusForbidden)
+			return
+		}
+
+		hash, err := user.HashPassword(req.NewPassword)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Все выданные токены отзываются: после смены пароля нужно войти заново
+		principal, _ := auth.PrincipalFromContext(r.Context())
+		if err := s.UpdateUserPassword(r.Context(), u.Id, hash, principal.TokenID, principal.TokenExpiresAt); err != nil {
+			internalError(w, r, "Ошибка при смене пароля", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// currentUser loads the user record of the caller. The static admin token has no
+// user record, so the endpoints working with own data are not available for it.
+func currentUser(s *postgresql.Storage, w http.ResponseWriter, r *http.Request) (user.User, bool) {
+	principal, _ := auth.PrincipalFromContext(r.Context())
+	if principal.UserID == 0 {
+		http.Error(w, "Доступно только для учётных записей пользователей", http.StatusForbidden)
+		return user.User{}, false
+	}
+
+	u, err := s.GetUserByID(r.Context(), principal.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		} else {
+			internalError(w, r, "Ошибка при получении пользователя", err)
+		}
+		return user.User{}, false
+	}
+
+	return u, true
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encodeJSON(r.Context(), w, v)
+}
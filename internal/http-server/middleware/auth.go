@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/P1coFly/vk_movies/internal/models/apikey"
+	"github.com/P1coFly/vk_movies/internal/models/user"
+)
+
+// Authenticated lets through authenticated callers having one of roles; with no roles
+// any authenticated caller is let through. The principal is put into the request context.
+func Authenticated(a *auth.Authenticator, roles ...user.Role) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := authenticate(w, r, a)
+			if !ok {
+				return
+			}
+			if len(roles) > 0 && !slices.Contains(roles, principal.Role) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// Scoped lets through authenticated callers having the scope: admins, and service clients whose API key has it.
+func Scoped(a *auth.Authenticator, scope apikey.Scope) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := authenticate(w, r, a)
+			if !ok {
+				return
+			}
+			if !principal.HasScope(scope) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// Public lets through anonymous requests. Requests with credentials are
+// authenticated and must have the scope, so an API key without it is refused.
+func Public(a *auth.Authenticator, scope apikey.Scope) router.Middleware {
+	scoped := Scoped(a, scope)
+	return func(next http.Handler) http.Handler {
+		withScope := scoped(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" && r.Header.Get(auth.APIKeyHeader) == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			withScope.ServeHTTP(w, r)
+		})
+	}
+}
+
+func authenticate(w http.ResponseWriter, r *http.Request, a *auth.Authenticator) (auth.Principal, bool) {
+	principal, err := a.Authenticate(r)
+	if err == nil {
+		return principal, true
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrNoCredentials) || errors.Is(err, auth.ErrInvalidCredentials):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="vk_movies"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	case errors.Is(err, auth.ErrLockedOut):
+		WriteLockedOut(w, err)
+	default:
+		http.Error(w, fmt.Sprintf("Ошибка при проверке авторизации: %s", err), http.StatusInternalServerError)
+	}
+	return principal, false
+}
+
+// WriteLockedOut answers 429 with the time the client has to wait in Retry-After.
+func WriteLockedOut(w http.ResponseWriter, err error) {
+	var lockout *auth.LockoutError
+	if errors.As(err, &lockout) {
+		w.Header().Set("Retry-After", strconv.Itoa(seconds(lockout.RetryAfter)))
+	}
+	http.Error(w, "Слишком много неудачных попыток, повторите позже", http.StatusTooManyRequests)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+)
+
+// CORS sets the Access-Control-* headers for requests from allowed origins and answers
+// preflight requests itself, so it must run before routing. Requests from other origins
+// are served without the headers and the browser refuses to expose the response.
+func CORS(cfg config.CORS) router.Middleware {
+	anyOrigin := slices.Contains(cfg.CORSAllowedOrigins, "*")
+	methods := strings.Join(cfg.CORSAllowedMethods, ", ")
+	headers := strings.Join(cfg.CORSAllowedHeaders, ", ")
+	exposed := strings.Join(cfg.CORSExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.CORSMaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if !anyOrigin {
+				w.Header().Add("Vary", "Origin")
+			}
+			if origin == "" || !(anyOrigin || slices.Contains(cfg.CORSAllowedOrigins, origin)) {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			if anyOrigin {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.CORSAllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				h.Set("Access-Control-Max-Age", maxAge)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if exposed != "" {
+				h.Set("Access-Control-Expose-Headers", exposed)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var testCORS = config.CORS{
+	CORSAllowedOrigins:   []string{"https://movies.example.com"},
+	CORSAllowedMethods:   []string{"GET", "POST"},
+	CORSAllowedHeaders:   []string{"Authorization", "Content-Type"},
+	CORSExposedHeaders:   []string{"X-Request-ID"},
+	CORSAllowCredentials: true,
+	CORSMaxAge:           10 * time.Minute,
+}
+
+func serveCORS(cfg config.CORS, r *http.Request) (*httptest.ResponseRecorder, bool) {
+	called := false
+	h := CORS(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w, called
+}
+
+func TestCORSPreflight(t *testing.T) {
+	r := httptest.NewRequest(http.MethodOptions, "/api/movies", nil)
+	r.Header.Set("Origin", "https://movies.example.com")
+	r.Header.Set("Access-Control-Request-Method", "POST")
+
+	w, called := serveCORS(testCORS, r)
+	assert.False(t, called, "preflight is answered by the middleware")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://movies.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+}
+
+func TestCORSRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/movies", nil)
+	r.Header.Set("Origin", "https://movies.example.com")
+
+	w, called := serveCORS(testCORS, r)
+	assert.True(t, called)
+	assert.Equal(t, "https://movies.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Request-ID", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Contains(t, w.Header().Values("Vary"), "Origin")
+}
+
+func TestCORSUnknownOrigin(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/movies", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+
+	w, called := serveCORS(testCORS, r)
+	assert.True(t, called)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	r = httptest.NewRequest(http.MethodOptions, "/api/movies", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	r.Header.Set("Access-Control-Request-Method", "DELETE")
+
+	w, called = serveCORS(testCORS, r)
+	assert.False(t, called)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	cfg := testCORS
+	cfg.CORSAllowedOrigins = []string{"*"}
+	cfg.CORSAllowCredentials = false
+
+	r := httptest.NewRequest(http.MethodGet, "/api/movies", nil)
+	r.Header.Set("Origin", "https://anyone.example.com")
+
+	w, _ := serveCORS(cfg, r)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/P1coFly/vk_movies/internal/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the request id. An id sent by the client or a proxy is kept,
+// so that the request can be followed across services.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID assigns the request an id, puts it into the context and the response header.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// GetRequestID returns the id assigned by RequestID.
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID accepts ids of reasonable length made of characters safe to log,
+// which covers UUIDs and the ids of common proxies.
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Logger puts a logger with the request id, route and trace id into the request context
+// and logs every request with its status, size and latency once it is served.
+func Logger(log *slog.Logger) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			l := log.With(
+				"request_id", GetRequestID(r.Context()),
+				"method", r.Method,
+				"path", r.URL.Path,
+				"route", router.Route(r.Context()),
+			)
+			if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+				l = l.With("trace_id", sc.TraceID().String())
+			}
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rec, r.WithContext(logger.With(r.Context(), l)))
+
+			level := slog.LevelInfo
+			if rec.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			l.Log(r.Context(), level, "request completed",
+				"status", rec.status,
+				"bytes", rec.bytes,
+				"latency_ms", float64(time.Since(start).Microseconds())/1000,
+				"ip", auth.ClientIP(r),
+			)
+		})
+	}
+}
+
+// responseRecorder remembers the status and size of the response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/P1coFly/vk_movies/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = GetRequestID(r.Context())
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(RequestIDHeader, "3f2c9a5e-1b7d-4c1e-9f7a-2d6b8e4a1c00")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, "3f2c9a5e-1b7d-4c1e-9f7a-2d6b8e4a1c00", seen, "incoming id is kept")
+	assert.Equal(t, seen, w.Header().Get(RequestIDHeader))
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(RequestIDHeader, "bad id\nwith newline")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Len(t, seen, 32, "invalid id is replaced")
+	assert.Equal(t, seen, w.Header().Get(RequestIDHeader))
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	rt := router.New()
+	rt.Use(RequestID, Logger(log))
+	rt.HandleFunc(http.MethodGet, "/api/movies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context()).Info("in handler")
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("short and stout"))
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/movies/7", nil)
+	r.Header.Set(RequestIDHeader, "req-1")
+	rt.ServeHTTP(httptest.NewRecorder(), r)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var handlerLog, requestLog map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &handlerLog))
+	require.NoError(t, json.Unmarshal(lines[1], &requestLog))
+
+	assert.Equal(t, "req-1", handlerLog["request_id"])
+	assert.Equal(t, "req-1", requestLog["request_id"])
+	assert.Equal(t, "GET /api/movies/{id}", requestLog["route"])
+	assert.Equal(t, "/api/movies/7", requestLog["path"])
+	assert.EqualValues(t, http.StatusTeapot, requestLog["status"])
+	assert.EqualValues(t, len("short and stout"), requestLog["bytes"])
+	assert.Contains(t, requestLog, "latency_ms")
+}
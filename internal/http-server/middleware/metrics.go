@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/P1coFly/vk_movies/internal/metrics"
+)
+
+// unmatchedRoute labels requests matching no route, so that scans of random paths
+// do not create a time series per path.
+const unmatchedRoute = "unmatched"
+
+// Metrics counts requests and observes their duration by route pattern, method and status.
+// It must run before Recover to count recovered panics as 500.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		route := router.Route(r.Context())
+		if route == "" {
+			route = unmatchedRoute
+		}
+		status := strconv.Itoa(rec.status)
+		metrics.HTTPRequests.WithLabelValues(route, r.Method, status).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/P1coFly/vk_movies/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	rt := router.New()
+	rt.Use(Metrics, Recover)
+	rt.HandleFunc(http.MethodGet, "/api/movies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	rt.HandleFunc(http.MethodGet, "/boom", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	requests := func(route, method, status string) float64 {
+		return testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(route, method, status))
+	}
+	movie := requests("GET /api/movies/{id}", http.MethodGet, "404")
+	panicked := requests("GET /boom", http.MethodGet, "500")
+	unmatched := requests(unmatchedRoute, http.MethodGet, "404")
+
+	for _, path := range []string{"/api/movies/1", "/api/movies/2", "/boom", "/wp-login.php"} {
+		rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Один ряд на шаблон маршрута, а не на каждый id
+	assert.Equal(t, movie+2, requests("GET /api/movies/{id}", http.MethodGet, "404"))
+	assert.Equal(t, panicked+1, requests("GET /boom", http.MethodGet, "500"))
+	assert.Equal(t, unmatched+1, requests(unmatchedRoute, http.MethodGet, "404"))
+}
@@ -0,0 +1,140 @@
+// Package middleware contains http middleware shared by the api routes.
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+)
+
+// RateLimiter keeps a token bucket per client. A bucket holds up to limit.Requests
+// tokens and is refilled evenly over limit.Period, every request takes a token.
+type RateLimiter struct {
+	limit config.Limit
+	rate  float64 // tokens per second
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func NewRateLimiter(limit config.Limit) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		rate:    float64(limit.Requests) / limit.Period.Seconds(),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Decision is the result of Allow.
+type Decision struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero if it is allowed now.
+	RetryAfter time.Duration
+}
+
+// Allow takes a token from the bucket of the client if there is one.
+func (l *RateLimiter) Allow(client string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	capacity := float64(l.limit.Requests)
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(capacity, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+
+	d := Decision{}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = l.duration(1 - b.tokens)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = l.duration(capacity - b.tokens)
+
+	return d
+}
+
+func (l *RateLimiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// sweep drops buckets that have been refilled completely, they are the same as new ones.
+// It runs at most once a period so that Allow stays cheap.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.limit.Period {
+		return
+	}
+	l.lastSweep = now
+
+	for client, b := range l.buckets {
+		if now.Sub(b.updated) >= l.limit.Period {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// RateLimit limits requests per client, answering 429 Too Many Requests with Retry-After
+// once the bucket is empty. Every response carries the RateLimit-* headers. It must run
+// after the access check of the route, which puts the principal into the context.
+func RateLimit(l *RateLimiter) router.Middleware {
+	policy := fmt.Sprintf("%d;w=%d", l.limit.Requests, int(math.Ceil(l.limit.Period.Seconds())))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := l.Allow(clientKey(r))
+
+			w.Header().Set("RateLimit-Policy", policy)
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(l.limit.Requests))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(d.Reset)))
+
+			if !d.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(seconds(d.RetryAfter)))
+				http.Error(w, "Слишком много запросов, повторите позже", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientKey identifies service clients by their API key and everyone else by IP address.
+// Only a key validated by the access check earlier in the chain gets its own bucket,
+// so made up keys can't be used to escape the limit of the address.
+func clientKey(r *http.Request) string {
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok && principal.APIKeyID != 0 {
+		return "key:" + strconv.FormatInt(principal.APIKeyID, 10)
+	}
+	return "ip:" + auth.ClientIP(r)
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(config.Limit{Requests: 2, Period: 10 * time.Second})
+	l.now = func() time.Time { return now }
+
+	d := l.Allow("a")
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 1, d.Remaining)
+	assert.Equal(t, 5*time.Second, d.Reset)
+
+	assert.True(t, l.Allow("a").Allowed)
+	d = l.Allow("a")
+	assert.False(t, d.Allowed)
+	assert.Equal(t, 0, d.Remaining)
+	assert.Equal(t, 5*time.Second, d.RetryAfter)
+
+	assert.True(t, l.Allow("b").Allowed, "clients have their own buckets")
+
+	now = now.Add(5 * time.Second)
+	assert.True(t, l.Allow("a").Allowed, "a token is refilled")
+	assert.False(t, l.Allow("a").Allowed)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	l := NewRateLimiter(config.Limit{Requests: 1, Period: time.Minute})
+	h := RateLimit(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(remoteAddr string, apiKeyID int64) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/movies/byTitleFragment?titleFragment=a", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("X-API-Key", "vkm_unchecked")
+		if apiKeyID != 0 {
+			// Ключ проверен middleware доступа
+			r = r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{APIKeyID: apiKeyID}))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := request("192.0.2.1:1000", 0)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1;w=60", w.Header().Get("RateLimit-Policy"))
+
+	w = request("192.0.2.1:2000", 0)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, request("192.0.2.1:3000", 7).Code, "validated api keys are limited separately")
+	assert.Equal(t, http.StatusTooManyRequests, request("192.0.2.1:3000", 7).Code)
+	assert.Equal(t, http.StatusOK, request("192.0.2.2:1000", 0).Code)
+}
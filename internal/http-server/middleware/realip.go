@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+)
+
+// RealIP resolves the address of the client behind trusted reverse proxies and puts it
+// into the context, where auth.ClientIP finds it. It must run first, so that logs, traces,
+// rate limits and lockouts all see the same client.
+func RealIP(proxies auth.TrustedProxies) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(auth.WithClientIP(r.Context(), proxies.ClientIP(r))))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/auth"
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealIP(t *testing.T) {
+	proxies, err := auth.ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	var got string
+	rt := router.New()
+	rt.Use(RealIP(proxies))
+	rt.HandleFunc(http.MethodGet, "/ip", func(w http.ResponseWriter, r *http.Request) {
+		got = auth.ClientIP(r)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	r.RemoteAddr = "10.1.2.3:4567"
+	r.Header.Set("X-Forwarded-For", "203.0.113.5")
+	rt.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "203.0.113.5", got)
+
+	r.RemoteAddr = "198.51.100.7:4567"
+	rt.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, "198.51.100.7", got)
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/P1coFly/vk_movies/internal/logger"
+	"github.com/P1coFly/vk_movies/internal/metrics"
+)
+
+// panicResponse is the body of the response to a request whose handler panicked.
+type panicResponse struct {
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Recover turns a panic in a handler into a logged error with the stack trace and a JSON 500,
+// so that one bad request does not drop the connection without a trace. If the handler has
+// already started the response, the panic is only logged and the response is aborted, since
+// a 500 can't be sent anymore. It must run after Logger to log with the request id.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			// Обработчик сам прервал ответ, это не ошибка
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			metrics.HTTPPanics.Inc()
+			logger.FromContext(r.Context()).Error("panic recovered",
+				"panic", fmt.Sprint(p),
+				"stack", string(debug.Stack()),
+			)
+
+			// Статус и часть тела уже отправлены: обрываем соединение, чтобы клиент не принял
+			// обрезанный ответ за полный
+			if rec.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(panicResponse{
+				Error:     http.StatusText(http.StatusInternalServerError),
+				RequestID: GetRequestID(r.Context()),
+			})
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/P1coFly/vk_movies/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	rt := router.New()
+	rt.Use(RequestID, Logger(log), Recover)
+	rt.HandleFunc(http.MethodGet, "/panic", func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]int
+		m["boom"]++
+	})
+
+	before := testutil.ToFloat64(metrics.HTTPPanics)
+	r := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	r.Header.Set(RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var body panicResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "req-42", body.RequestID)
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.HTTPPanics))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var panicLog, requestLog map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &panicLog))
+	require.NoError(t, json.Unmarshal(lines[1], &requestLog))
+
+	assert.Equal(t, "panic recovered", panicLog["msg"])
+	assert.Equal(t, "req-42", panicLog["request_id"])
+	assert.Contains(t, panicLog["stack"], "recover_test.go")
+	assert.EqualValues(t, http.StatusInternalServerError, requestLog["status"])
+}
+
+func TestRecoverAfterResponseStarted(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	rt := router.New()
+	rt.Use(RequestID, Logger(log), Recover)
+	rt.HandleFunc(http.MethodGet, "/panic", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("partial"))
+		panic("boom")
+	})
+
+	before := testutil.ToFloat64(metrics.HTTPPanics)
+	w := httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "partial", w.Body.String(), "nothing is appended to a started response")
+	assert.Equal(t, before+1, testutil.ToFloat64(metrics.HTTPPanics))
+	assert.Contains(t, buf.String(), "panic recovered")
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var buf bytes.Buffer
+	rt := router.New()
+	rt.Use(RequestID, Tracing, Logger(slog.New(slog.NewJSONHandler(&buf, nil))), Recover)
+	rt.HandleFunc(http.MethodGet, "/api/movies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/movies/7", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rt.ServeHTTP(httptest.NewRecorder(), r)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	span := ended[0]
+	assert.Equal(t, "GET /api/movies/{id}", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Contains(t, span.Attributes(), attribute.String("http.route", "/api/movies/{id}"))
+	assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+
+	// Записи лога запроса связаны с трассой
+	var entry map[string]any
+	require.NoError(t, json.NewDecoder(&buf).Decode(&entry))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry["trace_id"])
+}
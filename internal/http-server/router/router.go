@@ -0,0 +1,63 @@
+// Package router dispatches requests by method and path and runs them through middleware.
+package router
+
+import (
+	"context"
+	"net/http"
+)
+
+// Middleware wraps a handler with behaviour shared by several routes.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h with middleware, the first one runs first.
+func Chain(h http.Handler, m ...Middleware) http.Handler {
+	for i := len(m) - 1; i >= 0; i-- {
+		h = m[i](h)
+	}
+	return h
+}
+
+// Router is a http.ServeMux with middleware. Routes are registered by method and
+// path pattern, path parameters like {id} are read with r.PathValue.
+type Router struct {
+	mux        *http.ServeMux
+	middleware []Middleware
+	handler    http.Handler
+}
+
+func New() *Router {
+	mux := http.NewServeMux()
+	return &Router{mux: mux, handler: mux}
+}
+
+// Use adds middleware run for every request, including requests matching no route.
+// Middleware runs in the order it is added, also across several calls.
+func (rt *Router) Use(m ...Middleware) {
+	rt.middleware = append(rt.middleware, m...)
+	rt.handler = Chain(rt.mux, rt.middleware...)
+}
+
+// Handle registers the handler for the method and path, wrapped with route middleware.
+func (rt *Router) Handle(method, path string, h http.Handler, m ...Middleware) {
+	rt.mux.Handle(method+" "+path, Chain(h, m...))
+}
+
+// HandleFunc is Handle for a handler function.
+func (rt *Router) HandleFunc(method, path string, h http.HandlerFunc, m ...Middleware) {
+	rt.Handle(method, path, h, m...)
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Шаблон маршрута нужен middleware до того, как запрос дойдёт до mux
+	_, pattern := rt.mux.Handler(r)
+	rt.handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, pattern)))
+}
+
+type routeKey struct{}
+
+// Route returns the pattern of the route matched by the request, e.g. "GET /api/movies/{id}".
+// It is empty for requests matching no route.
+func Route(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
+}
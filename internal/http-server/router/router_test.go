@@ -0,0 +1,61 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/http-server/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func tag(name string, trace *[]string) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*trace = append(*trace, name+":"+router.Route(r.Context()))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRouter(t *testing.T) {
+	var trace []string
+	rt := router.New()
+	rt.Use(tag("global", &trace))
+	rt.HandleFunc(http.MethodGet, "/api/movies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("movie " + r.PathValue("id")))
+	}, tag("first", &trace), tag("second", &trace))
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/movies/42", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "movie 42", w.Body.String())
+	assert.Equal(t, []string{
+		"global:GET /api/movies/{id}",
+		"first:GET /api/movies/{id}",
+		"second:GET /api/movies/{id}",
+	}, trace)
+
+	trace = nil
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/movies/42", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.True(t, strings.Contains(w.Header().Get("Allow"), http.MethodGet))
+	assert.Equal(t, []string{"global:"}, trace, "route middleware does not run for unmatched requests")
+
+	w = httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRouterUseOrder(t *testing.T) {
+	var trace []string
+	rt := router.New()
+	rt.Use(tag("first", &trace), tag("second", &trace))
+	rt.Use(tag("third", &trace))
+	rt.HandleFunc(http.MethodGet, "/api/actors", func(w http.ResponseWriter, r *http.Request) {})
+
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/actors", nil))
+	assert.Equal(t, []string{"first:GET /api/actors", "second:GET /api/actors", "third:GET /api/actors"}, trace)
+}
@@ -0,0 +1,23 @@
+// Package logger carries the request scoped slog logger in a context, so that
+// everything done for a request is logged with its request id.
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// With returns a copy of ctx carrying the logger.
+func With(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger stored by With, or the default logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
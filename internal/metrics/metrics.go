@@ -0,0 +1,55 @@
+// Package metrics defines the Prometheus metrics of the service. They are registered
+// in the default registry and served at /metrics.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "vk_movies"
+
+var (
+	// HTTPRequests counts served requests by route pattern, method and status.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of served HTTP requests.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration observes the time to serve a request.
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time to serve an HTTP request.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	// HTTPPanics counts panics recovered in handlers.
+	HTTPPanics = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_panics_total",
+		Help:      "Number of panics recovered in HTTP handlers.",
+	})
+
+	// StorageQueryDuration observes storage calls by their op, e.g. storage.postgresql.GetActors.
+	StorageQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_query_duration_seconds",
+		Help:      "Time of a storage operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"op"})
+)
+
+func init() {
+	prometheus.MustRegister(collectors.NewBuildInfoCollector())
+}
+
+// ObserveQuery records the duration of the storage operation started at start.
+// It is deferred at the start of storage methods: defer metrics.ObserveQuery(op, time.Now()).
+func ObserveQuery(op string, start time.Time) {
+	StorageQueryDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+}
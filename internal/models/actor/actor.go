@@ -1,9 +1,37 @@
 package actor
 
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/P1coFly/vk_movies/internal/models/credit"
+)
+
+// Actor is any person credited in movies, not only an actor. Films lists the titles
+// of all the movies the person is credited in.
 type Actor struct {
 	Id       int64
 	Name     string
 	Sex      string
 	Birthday string
 	Films    string
+	// Credits are filled in for a single person.
+	Credits []credit.Credit `json:",omitempty"`
+}
+
+func New(name, sex, birthday string) (*Actor, error) {
+	const op = "models.actor.New"
+
+	if len(name) < 1 {
+		return nil, fmt.Errorf("%s: the name must not be empty", op)
+	}
+	if utf8.RuneCountInString(sex) != 1 {
+		return nil, fmt.Errorf("%s: the sex must be a single character", op)
+	}
+	if _, err := time.Parse(time.DateOnly, birthday); err != nil {
+		return nil, fmt.Errorf("%s: the birthday must be in the format YYYY-MM-DD", op)
+	}
+
+	return &Actor{Name: name, Sex: sex, Birthday: birthday}, nil
 }
@@ -0,0 +1,54 @@
+package apikey
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+type Scope string
+
+const (
+	ScopeCatalogRead Scope = "catalog:read"
+	ScopeMoviesWrite Scope = "movies:write"
+	ScopeActorsWrite Scope = "actors:write"
+)
+
+// Scopes lists every known scope.
+var Scopes = []Scope{ScopeCatalogRead, ScopeMoviesWrite, ScopeActorsWrite}
+
+// APIKey is a key of a service client. Only the hash of the key is stored,
+// Prefix is kept to tell keys apart in listings.
+type APIKey struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       []byte     `json:"-"`
+	Scopes     []Scope    `json:"scopes"`
+	CreatedBy  *int64     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func New(name string, scopes []Scope) (*APIKey, error) {
+	const op = "models.apikey.New"
+
+	if len(name) < 1 || len(name) > 100 {
+		return nil, fmt.Errorf("%s: the name length must be from 1 to 100", op)
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%s: at least one scope is required", op)
+	}
+	for _, s := range scopes {
+		if !slices.Contains(Scopes, s) {
+			return nil, fmt.Errorf("%s: unknown scope %q", op, s)
+		}
+	}
+
+	return &APIKey{Name: name, Scopes: scopes}, nil
+}
+
+func (k *APIKey) HasScope(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
@@ -0,0 +1,57 @@
+package credit
+
+import (
+	"fmt"
+	"slices"
+	"unicode/utf8"
+)
+
+type Role string
+
+const (
+	RoleActor    Role = "actor"
+	RoleDirector Role = "director"
+	RoleWriter   Role = "writer"
+	RoleProducer Role = "producer"
+)
+
+// Roles lists every known role.
+var Roles = []Role{RoleActor, RoleDirector, RoleWriter, RoleProducer}
+
+// Credit is the part a person took in a movie. A person may have several credits
+// in one movie with different roles. CharacterName and BillingOrder are set only
+// for actors, the lead has BillingOrder 1 and zero means the actor is not billed.
+type Credit struct {
+	MovieID       int64  `json:"movie_id"`
+	MovieTitle    string `json:"movie_title,omitempty"`
+	PersonID      int64  `json:"person_id"`
+	PersonName    string `json:"person_name,omitempty"`
+	Role          Role   `json:"role"`
+	CharacterName string `json:"character_name,omitempty"`
+	BillingOrder  int    `json:"billing_order,omitempty"`
+}
+
+func New(personID int64, role Role, characterName string, billingOrder int) (*Credit, error) {
+	const op = "models.credit.New"
+
+	if personID < 1 {
+		return nil, fmt.Errorf("%s: the person id must be positive", op)
+	}
+	if !slices.Contains(Roles, role) {
+		return nil, fmt.Errorf("%s: unknown role %q", op, role)
+	}
+	if characterName != "" && role != RoleActor {
+		return nil, fmt.Errorf("%s: only actors may have a character name", op)
+	}
+	if billingOrder < 0 {
+		return nil, fmt.Errorf("%s: the billing order must be positive", op)
+	}
+	if billingOrder != 0 && role != RoleActor {
+		return nil, fmt.Errorf("%s: only actors may have a billing order", op)
+	}
+	if utf8.RuneCountInString(characterName) > 200 {
+		return nil, fmt.Errorf("%s: the character name length must be not greater than 200", op)
+	}
+
+	return &Credit{PersonID: personID, Role: role, CharacterName: characterName, BillingOrder: billingOrder}, nil
+}
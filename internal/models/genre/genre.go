@@ -0,0 +1,24 @@
+package genre
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type Genre struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// New validates the name of a genre, surrounding spaces are dropped.
+func New(name string) (*Genre, error) {
+	const op = "models.genre.New"
+
+	name = strings.TrimSpace(name)
+	if n := utf8.RuneCountInString(name); n < 1 || n > 50 {
+		return nil, fmt.Errorf("%s: the name length must be from 1 to 50", op)
+	}
+
+	return &Genre{Name: name}, nil
+}
@@ -2,6 +2,9 @@ package movie
 
 import (
 	"fmt"
+
+	"github.com/P1coFly/vk_movies/internal/models/credit"
+	"github.com/P1coFly/vk_movies/internal/models/genre"
 )
 
 type Movie struct {
@@ -10,6 +13,15 @@ type Movie struct {
 	Description string  `json:"description"`
 	DateOfIssue string  `json:"date_of_issue"`
 	Rating      float64 `json:"rating"`
+	// Genres are filled in for a single movie and the movie list, they are assigned
+	// by ids separately from the other fields.
+	Genres []genre.Genre `json:"genres,omitempty"`
+	// Credits are filled in for a single movie.
+	Credits []credit.Credit `json:"credits,omitempty"`
+	// UserRating is the average score of the user reviews next to the editorial Rating,
+	// it is filled in with the number of reviews for a single movie and the movie list.
+	UserRating *float64 `json:"user_rating,omitempty"`
+	Votes      int      `json:"votes,omitempty"`
 }
 
 func New(title, description, dateOfIssue string, rating float64) (*Movie, error) {
@@ -27,3 +39,64 @@ func New(title, description, dateOfIssue string, rating float64) (*Movie, error)
 
 	return &Movie{Title: title, Description: description, DateOfIssue: dateOfIssue, Rating: rating}, nil
 }
+
+// SearchResult is a movie found by full-text search. Headline and Snippet are the title
+// and a fragment of the description escaped as HTML, with the matched words wrapped in <b></b>.
+type SearchResult struct {
+	Movie
+	Rank     float64 `json:"rank"`
+	Headline string  `json:"headline"`
+	Snippet  string  `json:"snippet"`
+}
+
+// Fields movies can be sorted by.
+const (
+	SortByID          = "id"
+	SortByTitle       = "title"
+	SortByRating      = "rating"
+	SortByDateOfIssue = "date_of_issue"
+)
+
+// Filter selects movies matching all of its set fields, zero fields select everything.
+type Filter struct {
+	// Title, Actor and Director are fragments of the title and of the name of one
+	// of the actors or directors.
+	Title    string
+	Actor    string
+	Director string
+	// DateFrom and DateTo bound the date of issue, YYYY-MM-DD, both inclusive.
+	DateFrom string
+	DateTo   string
+	// RatingMin and RatingMax bound the rating, both inclusive.
+	RatingMin *float64
+	RatingMax *float64
+	// GenreIDs selects movies having all of the genres.
+	GenreIDs []int64
+
+	// Sort is one of the SortBy fields, by id if empty.
+	Sort string
+	Desc bool
+	// Limit of zero means no limit.
+	Limit  int
+	Offset int
+}
+
+// Appearance is a movie of an actor's filmography with the part the actor played,
+// BillingOrder is zero if the actor is not billed.
+type Appearance struct {
+	Movie
+	CharacterName string `json:"character_name,omitempty"`
+	BillingOrder  int    `json:"billing_order,omitempty"`
+}
+
+// ActorMatch is a movie found by the names of its actors, MatchedActors are the actors
+// whose names matched, the most similar first.
+type ActorMatch struct {
+	Movie
+	MatchedActors []MatchedActor `json:"matched_actors"`
+}
+
+type MatchedActor struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,33 @@
+package review
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// Review is the score from 0 to 10 and the text a user gave a movie,
+// a user has at most one review of a movie.
+type Review struct {
+	Id        int64     `json:"id"`
+	MovieID   int64     `json:"movie_id"`
+	UserID    int64     `json:"user_id"`
+	Login     string    `json:"login"`
+	Score     int       `json:"score"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func New(score int, text string) (*Review, error) {
+	const op = "models.review.New"
+
+	if score < 0 || score > 10 {
+		return nil, fmt.Errorf("%s: the score must be in the range from 0 to 10", op)
+	}
+	if utf8.RuneCountInString(text) > 5000 {
+		return nil, fmt.Errorf("%s: the text length must be not greater than 5000", op)
+	}
+
+	return &Review{Score: score, Text: text}, nil
+}
@@ -0,0 +1,15 @@
+package suggestion
+
+type Kind string
+
+const (
+	KindMovie Kind = "movie"
+	KindActor Kind = "actor"
+)
+
+// Suggestion is a movie title or an actor name starting with the typed prefix.
+type Suggestion struct {
+	Kind Kind   `json:"kind"`
+	Id   int64  `json:"id"`
+	Text string `json:"text"`
+}
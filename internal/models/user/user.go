@@ -0,0 +1,69 @@
+package user
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+var loginRe = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,64}$`)
+
+type User struct {
+	Id           int64     `json:"id"`
+	Login        string    `json:"login"`
+	PasswordHash string    `json:"-"`
+	Role         Role      `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// New validates the user data and hashes the password.
+func New(login, password string, role Role) (*User, error) {
+	const op = "models.user.New"
+
+	if !loginRe.MatchString(login) {
+		return nil, fmt.Errorf("%s: the login must be 3 to 64 latin letters, digits or _.- characters", op)
+	}
+	if !role.Valid() {
+		return nil, fmt.Errorf("%s: unknown role %q", op, role)
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &User{Login: login, PasswordHash: hash, Role: role}, nil
+}
+
+// HashPassword validates the password length and returns its bcrypt hash.
+func HashPassword(password string) (string, error) {
+	// bcrypt учитывает только первые 72 байта пароля
+	if len(password) < 8 || len(password) > 72 {
+		return "", fmt.Errorf("the password length must be from 8 to 72 bytes")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// CheckPassword reports whether the password matches the stored hash.
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+func (r Role) Valid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
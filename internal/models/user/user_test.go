@@ -0,0 +1,37 @@
+package user_test
+
+import (
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHashesPassword(t *testing.T) {
+	u, err := user.New("john.doe", "correct horse", user.RoleUser)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, "correct horse", u.PasswordHash)
+	assert.True(t, u.CheckPassword("correct horse"))
+	assert.False(t, u.CheckPassword("wrong horse"))
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name, login, password string
+		role                  user.Role
+	}{
+		{"short login", "jo", "password1", user.RoleUser},
+		{"login with spaces", "john doe", "password1", user.RoleUser},
+		{"short password", "john", "short", user.RoleUser},
+		{"unknown role", "john", "password1", user.Role("root")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := user.New(tt.login, tt.password, tt.role)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public."ACTORS_MOVIES";
+DROP TABLE IF EXISTS public."MOVIES";
+DROP TABLE IF EXISTS public."ACTORS";
@@ -0,0 +1,29 @@
+-- Базовая схема. IF NOT EXISTS позволяет принять под управление базы,
+-- созданные старым init.sql.
+CREATE TABLE IF NOT EXISTS public."ACTORS"
+(
+    id bigserial NOT NULL,
+    name text,
+    sex Char(1),
+    birthday DATE,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public."MOVIES"
+(
+    id bigserial NOT NULL,
+    title VARCHAR(150) CHECK (LENGTH(title) >0),
+    description VARCHAR(1000),
+    date_of_issue DATE,
+    rating DECIMAL(3,1) CHECK (rating >= 0 AND rating <= 10),
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS public."ACTORS_MOVIES"
+(
+    actor_id BIGINT,
+    movie_id BIGINT,
+    PRIMARY KEY (actor_id, movie_id),
+    FOREIGN KEY (actor_id) REFERENCES public."ACTORS" (id) ON DELETE CASCADE,
+    FOREIGN KEY (movie_id) REFERENCES public."MOVIES" (id) ON DELETE CASCADE
+);
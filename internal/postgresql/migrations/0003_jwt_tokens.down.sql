@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public."REVOKED_TOKENS";
+DROP TABLE IF EXISTS public."REFRESH_TOKENS";
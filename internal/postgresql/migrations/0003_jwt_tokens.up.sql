@@ -0,0 +1,25 @@
+-- Refresh-токены одной цепочки ротации имеют общий family_id. Повторное использование
+-- уже обменянного токена отзывает всю цепочку.
+CREATE TABLE public."REFRESH_TOKENS"
+(
+    token_hash BYTEA NOT NULL,
+    user_id BIGINT NOT NULL,
+    family_id TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (token_hash),
+    FOREIGN KEY (user_id) REFERENCES public."USERS" (id) ON DELETE CASCADE
+);
+
+CREATE INDEX ON public."REFRESH_TOKENS" (family_id);
+CREATE INDEX ON public."REFRESH_TOKENS" (user_id);
+
+-- Список отозванных access-токенов (jti) до истечения их срока действия
+CREATE TABLE public."REVOKED_TOKENS"
+(
+    jti TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (jti)
+);
@@ -0,0 +1,15 @@
+-- Ключи сервисных клиентов. Хранится только sha256 ключа
+CREATE TABLE public."API_KEYS"
+(
+    id bigserial NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash BYTEA NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    FOREIGN KEY (created_by) REFERENCES public."USERS" (id) ON DELETE SET NULL
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.movies_search_vector_idx;
+ALTER TABLE public."MOVIES" DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,10 @@
+-- Полнотекстовый поиск по названию и описанию фильмов. Название весомее описания (A и B).
+-- Русская конфигурация стеммит кириллицу, английская — латиницу, лексемы обеих складываются.
+ALTER TABLE public."MOVIES" ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian'::regconfig, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english'::regconfig, coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('russian'::regconfig, coalesce(description, '')), 'B') ||
+    setweight(to_tsvector('english'::regconfig, coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX movies_search_vector_idx ON public."MOVIES" USING GIN (search_vector);
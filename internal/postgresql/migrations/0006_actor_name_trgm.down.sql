@@ -0,0 +1,2 @@
+-- Расширение остаётся: его мог создать администратор, и им могут пользоваться другие объекты
+DROP INDEX IF EXISTS public.actors_name_trgm_idx;
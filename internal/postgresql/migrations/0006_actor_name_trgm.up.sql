@@ -0,0 +1,6 @@
+-- Нечёткий поиск актёров по триграммам. pg_trgm — доверенное расширение, но на базах,
+-- где у api_service нет права CREATE на базу, его заранее создаёт администратор (см. init.sql
+-- и раздел «Миграции схемы» в README); migrate up проверяет это до применения миграций.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX actors_name_trgm_idx ON public."ACTORS" USING GIN (name gin_trgm_ops);
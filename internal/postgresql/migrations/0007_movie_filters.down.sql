@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.movies_rating_idx;
+DROP INDEX IF EXISTS public.movies_date_of_issue_idx;
+DROP INDEX IF EXISTS public.movies_title_trgm_idx;
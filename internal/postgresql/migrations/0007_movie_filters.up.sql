@@ -0,0 +1,4 @@
+-- Индексы фильтров списка фильмов: фрагмент названия (ILIKE), даты выхода и рейтинга
+CREATE INDEX movies_title_trgm_idx ON public."MOVIES" USING GIN (title gin_trgm_ops);
+CREATE INDEX movies_date_of_issue_idx ON public."MOVIES" (date_of_issue);
+CREATE INDEX movies_rating_idx ON public."MOVIES" (rating);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.actors_name_prefix_idx;
+DROP INDEX IF EXISTS public.movies_title_prefix_idx;
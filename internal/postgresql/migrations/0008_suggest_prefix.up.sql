@@ -0,0 +1,3 @@
+-- Индексы подсказок: поиск по началу названия и имени без учёта регистра (LIKE 'префикс%')
+CREATE INDEX movies_title_prefix_idx ON public."MOVIES" (lower(title) text_pattern_ops);
+CREATE INDEX actors_name_prefix_idx ON public."ACTORS" (lower(name) text_pattern_ops);
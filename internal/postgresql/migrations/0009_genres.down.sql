@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public."MOVIES_GENRES";
+DROP TABLE IF EXISTS public."GENRES";
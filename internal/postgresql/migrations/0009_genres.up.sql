@@ -0,0 +1,19 @@
+-- Жанры фильмов, связь многие-ко-многим
+CREATE TABLE public."GENRES"
+(
+    id bigserial NOT NULL,
+    name VARCHAR(50) NOT NULL CHECK (LENGTH(name) > 0),
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX genres_name_idx ON public."GENRES" (lower(name));
+
+CREATE TABLE public."MOVIES_GENRES"
+(
+    movie_id BIGINT,
+    genre_id BIGINT,
+    PRIMARY KEY (movie_id, genre_id),
+    FOREIGN KEY (movie_id) REFERENCES public."MOVIES" (id) ON DELETE CASCADE,
+    FOREIGN KEY (genre_id) REFERENCES public."GENRES" (id) ON DELETE CASCADE
+);
+-- Фильтр по жанру ищет фильмы по genre_id
+CREATE INDEX movies_genres_genre_idx ON public."MOVIES_GENRES" (genre_id);
@@ -0,0 +1,14 @@
+-- Остальные роли и имена персонажей при откате теряются
+CREATE TABLE public."ACTORS_MOVIES"
+(
+    actor_id BIGINT,
+    movie_id BIGINT,
+    PRIMARY KEY (actor_id, movie_id),
+    FOREIGN KEY (actor_id) REFERENCES public."ACTORS" (id) ON DELETE CASCADE,
+    FOREIGN KEY (movie_id) REFERENCES public."MOVIES" (id) ON DELETE CASCADE
+);
+
+INSERT INTO public."ACTORS_MOVIES" (actor_id, movie_id)
+SELECT person_id, movie_id FROM public."CREDITS" WHERE role = 'actor';
+
+DROP TABLE public."CREDITS";
@@ -0,0 +1,18 @@
+-- Участие людей в фильмах с ролью. Актёры из ACTORS_MOVIES переносятся с ролью actor,
+-- таблица ACTORS теперь хранит всех людей: актёров, режиссёров, сценаристов и продюсеров.
+CREATE TABLE public."CREDITS"
+(
+    movie_id BIGINT NOT NULL,
+    person_id BIGINT NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('actor', 'director', 'writer', 'producer')),
+    character_name VARCHAR(200) CHECK (role = 'actor' OR character_name IS NULL),
+    PRIMARY KEY (movie_id, person_id, role),
+    FOREIGN KEY (movie_id) REFERENCES public."MOVIES" (id) ON DELETE CASCADE,
+    FOREIGN KEY (person_id) REFERENCES public."ACTORS" (id) ON DELETE CASCADE
+);
+CREATE INDEX credits_person_idx ON public."CREDITS" (person_id, role);
+
+INSERT INTO public."CREDITS" (movie_id, person_id, role)
+SELECT movie_id, actor_id, 'actor' FROM public."ACTORS_MOVIES";
+
+DROP TABLE public."ACTORS_MOVIES";
@@ -0,0 +1,3 @@
+ALTER TABLE public."CREDITS"
+    DROP CONSTRAINT IF EXISTS credits_billing_order_actor_check,
+    DROP COLUMN IF EXISTS billing_order;
@@ -0,0 +1,5 @@
+-- Порядок актёра в титрах: 1 — главная роль. У прежних записей порядок не задан,
+-- такие актёры идут после указанных.
+ALTER TABLE public."CREDITS"
+    ADD COLUMN billing_order INT CHECK (billing_order > 0),
+    ADD CONSTRAINT credits_billing_order_actor_check CHECK (role = 'actor' OR billing_order IS NULL);
@@ -0,0 +1,17 @@
+-- Отзывы пользователей: одна оценка от 0 до 10 с текстом от пользователя на фильм.
+-- Уникальный ключ (movie_id, user_id) служит и индексом для отзывов и оценок фильма.
+CREATE TABLE public."REVIEWS"
+(
+    id bigserial NOT NULL,
+    movie_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    score SMALLINT NOT NULL CHECK (score >= 0 AND score <= 10),
+    text VARCHAR(5000) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    UNIQUE (movie_id, user_id),
+    FOREIGN KEY (movie_id) REFERENCES public."MOVIES" (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES public."USERS" (id) ON DELETE CASCADE
+);
+CREATE INDEX reviews_user_idx ON public."REVIEWS" (user_id);
@@ -0,0 +1 @@
+ALTER TABLE public."USERS" DROP COLUMN IF EXISTS tokens_valid_after;
@@ -0,0 +1,3 @@
+-- Access-токены, выданные раньше tokens_valid_after, недействительны: смена пароля
+-- завершает все сессии пользователя, а не только ту, из которой она сделана
+ALTER TABLE public."USERS" ADD COLUMN tokens_valid_after TIMESTAMPTZ;
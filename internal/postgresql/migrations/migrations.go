@@ -0,0 +1,10 @@
+// Package migrations contains versioned SQL migrations of the VK_MOVIES schema.
+//
+// Every migration is a pair of files NNNN_name.up.sql and NNNN_name.down.sql,
+// the files are embedded into the binary and applied by the `migrate` command.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,209 @@
+// Package seed loads fixture sets (actors and movies) into the storage.
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/P1coFly/vk_movies/internal/models/actor"
+	"github.com/P1coFly/vk_movies/internal/models/movie"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures is a set of actors and movies to load.
+type Fixtures struct {
+	Actors []Actor `json:"actors" yaml:"actors"`
+	Movies []Movie `json:"movies" yaml:"movies"`
+}
+
+// Actor is an actor fixture. Key is used to reference the actor from movies.
+type Actor struct {
+	Key      string `json:"key" yaml:"key"`
+	Name     string `json:"name" yaml:"name"`
+	Sex      string `json:"sex" yaml:"sex"`
+	Birthday string `json:"birthday" yaml:"birthday"`
+}
+
+// Movie is a movie fixture. Actors holds keys of actor fixtures.
+type Movie struct {
+	Title       string   `json:"title" yaml:"title"`
+	Description string   `json:"description" yaml:"description"`
+	DateOfIssue string   `json:"date_of_issue" yaml:"date_of_issue"`
+	Rating      float64  `json:"rating" yaml:"rating"`
+	Actors      []string `json:"actors" yaml:"actors"`
+}
+
+// Result counts what Apply has created and what already existed.
+type Result struct {
+	ActorsCreated int
+	ActorsSkipped int
+	MoviesCreated int
+	MoviesSkipped int
+}
+
+// Load reads fixtures from a .yaml, .yml or .json file or from every such file in a directory.
+func Load(path string) (*Fixtures, error) {
+	const op = "seed.Load"
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		files = files[:0]
+		for _, e := range entries {
+			if !e.IsDir() && isFixtureFile(e.Name()) {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var f Fixtures
+	for _, file := range files {
+		part, err := loadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, file, err)
+		}
+		f.Actors = append(f.Actors, part.Actors...)
+		f.Movies = append(f.Movies, part.Movies...)
+	}
+
+	return &f, nil
+}
+
+func isFixtureFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+func loadFile(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f Fixtures
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &f)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &f)
+	default:
+		err = fmt.Errorf("unsupported fixture format")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// Validate checks every fixture with the model constructors and resolves actor keys.
+func (f *Fixtures) Validate() error {
+	keys := make(map[string]bool, len(f.Actors))
+	for _, a := range f.Actors {
+		if a.Key == "" {
+			return fmt.Errorf("actor %q has no key", a.Name)
+		}
+		if keys[a.Key] {
+			return fmt.Errorf("duplicate actor key %q", a.Key)
+		}
+		keys[a.Key] = true
+		if _, err := actor.New(a.Name, a.Sex, a.Birthday); err != nil {
+			return fmt.Errorf("actor %q: %w", a.Key, err)
+		}
+	}
+
+	for _, m := range f.Movies {
+		if _, err := movie.New(m.Title, m.Description, m.DateOfIssue, m.Rating); err != nil {
+			return fmt.Errorf("movie %q: %w", m.Title, err)
+		}
+		for _, key := range m.Actors {
+			if !keys[key] {
+				return fmt.Errorf("movie %q references unknown actor %q", m.Title, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Apply saves fixtures through the storage. Actors are matched by name and birthday,
+// movies by title and date of issue, so running Apply again creates nothing new.
+func Apply(ctx context.Context, s *postgresql.Storage, f *Fixtures) (Result, error) {
+	const op = "seed.Apply"
+	var res Result
+
+	// Проверяем все фикстуры до записи, чтобы не загрузить набор частично
+	if err := f.Validate(); err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+
+	actorIDs := make(map[string]int64, len(f.Actors))
+	for _, a := range f.Actors {
+		id, err := s.FindActorID(ctx, a.Name, a.Birthday)
+		switch {
+		case err == nil:
+			res.ActorsSkipped++
+		case errors.Is(err, storage.ErrActorNotFound):
+			if err := s.SaveActor(ctx, a.Name, a.Sex, a.Birthday); err != nil {
+				return res, fmt.Errorf("%s: %w", op, err)
+			}
+			if id, err = s.FindActorID(ctx, a.Name, a.Birthday); err != nil {
+				return res, fmt.Errorf("%s: %w", op, err)
+			}
+			res.ActorsCreated++
+		default:
+			return res, fmt.Errorf("%s: %w", op, err)
+		}
+		actorIDs[a.Key] = id
+	}
+
+	for _, fm := range f.Movies {
+		ids := make([]int64, 0, len(fm.Actors))
+		for _, key := range fm.Actors {
+			ids = append(ids, actorIDs[key])
+		}
+
+		movieID, err := s.FindMovieID(ctx, fm.Title, fm.DateOfIssue)
+		switch {
+		case err == nil:
+			res.MoviesSkipped++
+		case errors.Is(err, storage.ErrMovieNotFound):
+			m, _ := movie.New(fm.Title, fm.Description, fm.DateOfIssue, fm.Rating)
+			if err := s.SaveMovie(ctx, *m, nil, nil); err != nil {
+				return res, fmt.Errorf("%s: %w", op, err)
+			}
+			if movieID, err = s.FindMovieID(ctx, fm.Title, fm.DateOfIssue); err != nil {
+				return res, fmt.Errorf("%s: %w", op, err)
+			}
+			res.MoviesCreated++
+		default:
+			return res, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err := s.AddActorsToMovie(ctx, movieID, ids); err != nil {
+			return res, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,87 @@
+package seed_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/seed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFixtureSets(t *testing.T) {
+	for _, dir := range []string{"../../fixtures/dev", "../../fixtures/test"} {
+		f, err := seed.Load(dir)
+		require.NoError(t, err, dir)
+
+		assert.NotEmpty(t, f.Actors, dir)
+		assert.NotEmpty(t, f.Movies, dir)
+		assert.NoError(t, f.Validate(), dir)
+	}
+}
+
+func TestLoadMergesFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "actors.yaml"), `
+actors:
+  - key: a
+    name: Actor
+    sex: M
+    birthday: "1990-01-01"
+`)
+	writeFile(t, filepath.Join(dir, "movies.json"), `{"movies": [{"title": "Movie", "date_of_issue": "2000-01-01", "rating": 5, "actors": ["a"]}]}`)
+	writeFile(t, filepath.Join(dir, "README.txt"), "ignored")
+
+	f, err := seed.Load(dir)
+	require.NoError(t, err)
+
+	assert.Len(t, f.Actors, 1)
+	assert.Len(t, f.Movies, 1)
+	assert.Equal(t, []string{"a"}, f.Movies[0].Actors)
+	assert.NoError(t, f.Validate())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		f    seed.Fixtures
+	}{
+		{
+			name: "unknown actor key",
+			f: seed.Fixtures{Movies: []seed.Movie{
+				{Title: "Movie", DateOfIssue: "2000-01-01", Actors: []string{"missing"}},
+			}},
+		},
+		{
+			name: "duplicate actor key",
+			f: seed.Fixtures{Actors: []seed.Actor{
+				{Key: "a", Name: "One", Sex: "M", Birthday: "1990-01-01"},
+				{Key: "a", Name: "Two", Sex: "F", Birthday: "1990-01-01"},
+			}},
+		},
+		{
+			name: "invalid rating",
+			f: seed.Fixtures{Movies: []seed.Movie{
+				{Title: "Movie", DateOfIssue: "2000-01-01", Rating: 11},
+			}},
+		},
+		{
+			name: "invalid birthday",
+			f: seed.Fixtures{Actors: []seed.Actor{
+				{Key: "a", Name: "One", Sex: "M", Birthday: "01.01.1990"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, tt.f.Validate())
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+}
@@ -0,0 +1,132 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/P1coFly/vk_movies/internal/models/apikey"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/lib/pq"
+)
+
+func (s *Storage) SaveAPIKey(ctx context.Context, k apikey.APIKey) (int64, error) {
+	const op = "storage.postgresql.SaveAPIKey"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `INSERT INTO public."API_KEYS" (name, prefix, key_hash, scopes, created_by) VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		k.Name, k.Prefix, k.Hash, pq.Array(scopesToStrings(k.Scopes)), k.CreatedBy).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetAPIKeys(ctx context.Context) ([]apikey.APIKey, error) {
+	const op = "storage.postgresql.GetAPIKeys"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, prefix, key_hash, scopes, created_by, created_at, last_used_at, revoked_at
+		FROM public."API_KEYS" ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	keys := []apikey.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+// GetActiveAPIKeyByHash returns the not revoked key with the given hash.
+func (s *Storage) GetActiveAPIKeyByHash(ctx context.Context, keyHash []byte) (apikey.APIKey, error) {
+	const op = "storage.postgresql.GetActiveAPIKeyByHash"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	k, err := scanAPIKey(s.db.QueryRowContext(ctx, `SELECT id, name, prefix, key_hash, scopes, created_by, created_at, last_used_at, revoked_at
+		FROM public."API_KEYS" WHERE key_hash = $1 AND revoked_at IS NULL`, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return k, fmt.Errorf("%s: %w", op, storage.ErrAPIKeyNotFound)
+	}
+	if err != nil {
+		return k, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return k, nil
+}
+
+// TouchAPIKey records the use of the key. The time is updated at most once
+// a minute so that busy clients do not turn every request into a write.
+func (s *Storage) TouchAPIKey(ctx context.Context, keyID int64) error {
+	const op = "storage.postgresql.TouchAPIKey"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	_, err := s.db.ExecContext(ctx, `UPDATE public."API_KEYS" SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')`, keyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) RevokeAPIKey(ctx context.Context, keyID int64) error {
+	const op = "storage.postgresql.RevokeAPIKey"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE public."API_KEYS" SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, keyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAPIKeyNotFound)
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row scanner) (apikey.APIKey, error) {
+	var k apikey.APIKey
+	var scopes []string
+	err := row.Scan(&k.Id, &k.Name, &k.Prefix, &k.Hash, pq.Array(&scopes), &k.CreatedBy, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+	for _, scope := range scopes {
+		k.Scopes = append(k.Scopes, apikey.Scope(scope))
+	}
+
+	return k, err
+}
+
+func scopesToStrings(scopes []apikey.Scope) []string {
+	res := make([]string, len(scopes))
+	for i, scope := range scopes {
+		res[i] = string(scope)
+	}
+	return res
+}
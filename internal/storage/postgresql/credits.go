@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/P1coFly/vk_movies/internal/models/credit"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/lib/pq"
+)
+
+// SetMovieCredits replaces the credits of the movie, an empty list removes them all.
+// An unknown person returns storage.ErrActorNotFound and the credits are kept.
+func (s *Storage) SetMovieCredits(ctx context.Context, movieID int64, credits []credit.Credit) error {
+	const op = "storage.postgresql.SetMovieCredits"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM public."MOVIES" WHERE id = $1)`, movieID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrMovieNotFound)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM public."CREDITS" WHERE movie_id = $1`, movieID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, c := range credits {
+		_, err := tx.ExecContext(ctx, `INSERT INTO public."CREDITS" (movie_id, person_id, role, character_name, billing_order)
+			VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, 0)) ON CONFLICT DO NOTHING`,
+			movieID, c.PersonID, c.Role, c.CharacterName, c.BillingOrder)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+				return fmt.Errorf("%s: %w", op, storage.ErrActorNotFound)
+			}
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetMovieCredits returns the credits of the movie grouped by role, actors first
+// in the billing order, then the actors without one.
+func (s *Storage) GetMovieCredits(ctx context.Context, movieID int64) ([]credit.Credit, error) {
+	const op = "storage.postgresql.GetMovieCredits"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	credits, err := s.queryCredits(ctx, `WHERE c.movie_id = $2 ORDER BY array_position($1::text[], c.role::text), c.billing_order NULLS LAST, a.name, a.id`,
+		pq.Array(credit.Roles), movieID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return credits, nil
+}
+
+// GetPersonCredits returns the credits of the person, the latest movies first.
+func (s *Storage) GetPersonCredits(ctx context.Context, personID int64) ([]credit.Credit, error) {
+	const op = "storage.postgresql.GetPersonCredits"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	credits, err := s.queryCredits(ctx, `WHERE c.person_id = $2 ORDER BY m.date_of_issue DESC, m.id, array_position($1::text[], c.role::text)`,
+		pq.Array(credit.Roles), personID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return credits, nil
+}
+
+func (s *Storage) queryCredits(ctx context.Context, where string, args ...any) ([]credit.Credit, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT c.movie_id, m.title, c.person_id, a.name, c.role, COALESCE(c.character_name, ''), COALESCE(c.billing_order, 0)
+		FROM public."CREDITS" c
+		JOIN public."MOVIES" m ON m.id = c.movie_id
+		JOIN public."ACTORS" a ON a.id = c.person_id `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credits := []credit.Credit{}
+	for rows.Next() {
+		var c credit.Credit
+		if err := rows.Scan(&c.MovieID, &c.MovieTitle, &c.PersonID, &c.PersonName, &c.Role, &c.CharacterName, &c.BillingOrder); err != nil {
+			return nil, err
+		}
+		credits = append(credits, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return credits, nil
+}
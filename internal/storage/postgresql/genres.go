@@ -0,0 +1,216 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/P1coFly/vk_movies/internal/models/genre"
+	"github.com/P1coFly/vk_movies/internal/models/movie"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/lib/pq"
+)
+
+// foreignKeyViolation is the PostgreSQL error code of a foreign key constraint violation.
+const foreignKeyViolation = "23503"
+
+func (s *Storage) SaveGenre(ctx context.Context, g genre.Genre) (int64, error) {
+	const op = "storage.postgresql.SaveGenre"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `INSERT INTO public."GENRES" (name) VALUES ($1) RETURNING id`, g.Name).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, genreError(err))
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetGenres(ctx context.Context) ([]genre.Genre, error) {
+	const op = "storage.postgresql.GetGenres"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name FROM public."GENRES" ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	genres := []genre.Genre{}
+	for rows.Next() {
+		var g genre.Genre
+		if err := rows.Scan(&g.Id, &g.Name); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		genres = append(genres, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return genres, nil
+}
+
+func (s *Storage) GetGenreByID(ctx context.Context, genreID int64) (genre.Genre, error) {
+	const op = "storage.postgresql.GetGenreByID"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	var g genre.Genre
+	err := s.db.QueryRowContext(ctx, `SELECT id, name FROM public."GENRES" WHERE id = $1`, genreID).Scan(&g.Id, &g.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return g, fmt.Errorf("%s: %w", op, storage.ErrGenreNotFound)
+	}
+	if err != nil {
+		return g, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return g, nil
+}
+
+func (s *Storage) UpdateGenre(ctx context.Context, genreID int64, newName string) error {
+	const op = "storage.postgresql.UpdateGenre"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE public."GENRES" SET name = $1 WHERE id = $2`, newName, genreID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, genreError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrGenreNotFound)
+	}
+
+	return nil
+}
+
+// DeleteGenreByID deletes the genre, the movies lose it.
+func (s *Storage) DeleteGenreByID(ctx context.Context, genreID int64) error {
+	const op = "storage.postgresql.DeleteGenreByID"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM public."GENRES" WHERE id = $1`, genreID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrGenreNotFound)
+	}
+
+	return nil
+}
+
+// SetMovieGenres replaces the genres of the movie, an empty list removes them all.
+func (s *Storage) SetMovieGenres(ctx context.Context, movieID int64, genreIDs []int64) error {
+	const op = "storage.postgresql.SetMovieGenres"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM public."MOVIES" WHERE id = $1)`, movieID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrMovieNotFound)
+	}
+
+	if err := replaceMovieGenres(ctx, tx, movieID, genreIDs); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func replaceMovieGenres(ctx context.Context, tx *tracedTx, movieID int64, genreIDs []int64) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM public."MOVIES_GENRES" WHERE movie_id = $1`, movieID); err != nil {
+		return err
+	}
+	return addMovieGenres(ctx, tx, movieID, genreIDs)
+}
+
+func addMovieGenres(ctx context.Context, tx *tracedTx, movieID int64, genreIDs []int64) error {
+	if len(genreIDs) == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO public."MOVIES_GENRES" (movie_id, genre_id)
+		SELECT $1::bigint, unnest($2::bigint[]) ON CONFLICT DO NOTHING`, movieID, pq.Array(genreIDs))
+	return genreError(err)
+}
+
+// genreError maps constraint violations on genres to the storage errors.
+func genreError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == uniqueViolation && pqErr.Table == "GENRES":
+		return storage.ErrGenreExists
+	case pqErr.Code == foreignKeyViolation && pqErr.Constraint == "MOVIES_GENRES_genre_id_fkey":
+		return storage.ErrGenreNotFound
+	}
+	return err
+}
+
+// withGenres fills in the genres of the movies with one query.
+func (s *Storage) withGenres(ctx context.Context, movies []movie.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(movies))
+	for i, m := range movies {
+		ids[i] = m.Id
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT mg.movie_id, g.id, g.name FROM public."MOVIES_GENRES" mg
+		JOIN public."GENRES" g ON g.id = mg.genre_id
+		WHERE mg.movie_id = ANY($1) ORDER BY g.name`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	genres := make(map[int64][]genre.Genre)
+	for rows.Next() {
+		var movieID int64
+		var g genre.Genre
+		if err := rows.Scan(&movieID, &g.Id, &g.Name); err != nil {
+			return err
+		}
+		genres[movieID] = append(genres[movieID], g)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range movies {
+		movies[i].Genres = genres[movies[i].Id]
+	}
+	return nil
+}
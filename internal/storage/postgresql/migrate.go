@@ -0,0 +1,351 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// migrationLockID is the key of the advisory lock taken while migrations run,
+// so that several instances started at once do not apply the same migration twice.
+const migrationLockID = 7_318_202_604
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var createExtensionRe = regexp.MustCompile(`(?i)CREATE\s+EXTENSION\s+(?:IF\s+NOT\s+EXISTS\s+)?"?(\w+)"?`)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus describes whether a migration has been applied.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrator applies migrations to the database of the Storage.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator reads migrations from fsys and returns a Migrator for the storage.
+func (s *Storage) NewMigrator(fsys fs.FS) (*Migrator, error) {
+	const op = "storage.postgresql.NewMigrator"
+
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{db: s.db.DB, migrations: migrations}, nil
+}
+
+func readMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := migrationFileRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", e.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies all pending migrations and returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	const op = "storage.postgresql.Migrator.Up"
+
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		var pending []Migration
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; !ok {
+				pending = append(pending, mig)
+			}
+		}
+		if err := checkExtensions(ctx, conn, pending); err != nil {
+			return err
+		}
+
+		for _, mig := range pending {
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `INSERT INTO public.schema_migrations (version, name) VALUES ($1, $2)`,
+					mig.Version, mig.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	if err != nil {
+		return applied, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return applied, nil
+}
+
+// Down rolls back the last steps applied migrations and returns the rolled back ones.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	const op = "storage.postgresql.Migrator.Down"
+
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM public.schema_migrations WHERE version = $1`, mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	if err != nil {
+		return reverted, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reverted, nil
+}
+
+// Status returns every known migration with the time it was applied, if any.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	const op = "storage.postgresql.Migrator.Status"
+
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			st := MigrationStatus{Version: mig.Version, Name: mig.Name}
+			if at, ok := done[mig.Version]; ok {
+				st.AppliedAt = &at
+			}
+			statuses = append(statuses, st)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return statuses, nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	if err := checkSchemaAccess(ctx, conn); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS public.schema_migrations
+	(
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM public.schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		done[version] = at
+	}
+
+	return done, rows.Err()
+}
+
+// checkSchemaAccess fails if the role can't create objects in the public schema or does not
+// own its tables, e.g. in databases created by the old init.sql, where the tables belong
+// to postgres. The error lists the statements an administrator has to run once.
+func checkSchemaAccess(ctx context.Context, conn *sql.Conn) error {
+	var role string
+	var canCreate bool
+	err := conn.QueryRowContext(ctx, `SELECT current_user, has_schema_privilege('public', 'CREATE')`).Scan(&role, &canCreate)
+	if err != nil {
+		return err
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT c.relname FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p') AND NOT pg_has_role(c.relowner, 'USAGE')
+		ORDER BY c.relname`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var fixes []string
+	if !canCreate {
+		fixes = append(fixes, fmt.Sprintf("GRANT USAGE, CREATE ON SCHEMA public TO %s;", pq.QuoteIdentifier(role)))
+	}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return err
+		}
+		fixes = append(fixes, fmt.Sprintf("ALTER TABLE public.%s OWNER TO %s;", pq.QuoteIdentifier(table), pq.QuoteIdentifier(role)))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(fixes) > 0 {
+		return fmt.Errorf("role %s can't change the schema, run as an administrator once: %s", role, strings.Join(fixes, " "))
+	}
+
+	return nil
+}
+
+// checkExtensions fails if a pending migration creates an extension that is not installed
+// and that the role can't create, without the right to create objects in the database.
+// Such extensions have to be created once by an administrator.
+func checkExtensions(ctx context.Context, conn *sql.Conn, pending []Migration) error {
+	for _, mig := range pending {
+		for _, name := range createdExtensions(mig.Up) {
+			var installed, canCreate bool
+			var role string
+			err := conn.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = $1),
+				has_database_privilege(current_database(), 'CREATE'), current_user`, name).Scan(&installed, &canCreate, &role)
+			if err != nil {
+				return err
+			}
+			if !installed && !canCreate {
+				return fmt.Errorf("migration %d_%s needs the %s extension, which role %s can't create: "+
+					"run CREATE EXTENSION %s; in the database as an administrator first", mig.Version, mig.Name, name, role, name)
+			}
+		}
+	}
+	return nil
+}
+
+// createdExtensions returns the names of the extensions created by the migration script.
+func createdExtensions(script string) []string {
+	var names []string
+	for _, match := range createExtensionRe.FindAllStringSubmatch(script, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
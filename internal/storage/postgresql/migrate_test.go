@@ -0,0 +1,29 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/postgresql/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatedExtensions(t *testing.T) {
+	assert.Equal(t, []string{"pg_trgm", "unaccent"}, createdExtensions(`
+		CREATE EXTENSION IF NOT EXISTS pg_trgm;
+		create extension "unaccent";
+		CREATE INDEX actors_name_trgm_idx ON public."ACTORS" USING GIN (name gin_trgm_ops);
+	`))
+	assert.Empty(t, createdExtensions(`DROP EXTENSION IF EXISTS pg_trgm;`))
+
+	all, err := readMigrations(migrations.FS)
+	require.NoError(t, err)
+	var found bool
+	for _, mig := range all {
+		if mig.Name == "actor_name_trgm" {
+			found = true
+			assert.Equal(t, []string{"pg_trgm"}, createdExtensions(mig.Up))
+		}
+	}
+	assert.True(t, found)
+}
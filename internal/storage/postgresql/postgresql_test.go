@@ -1,17 +1,30 @@
 package postgresql_test
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/P1coFly/vk_movies/internal/models/actor"
+	"github.com/P1coFly/vk_movies/internal/models/credit"
+	"github.com/P1coFly/vk_movies/internal/models/genre"
 	"github.com/P1coFly/vk_movies/internal/models/movie"
+	"github.com/P1coFly/vk_movies/internal/models/review"
+	"github.com/P1coFly/vk_movies/internal/models/suggestion"
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	pkgstorage "github.com/P1coFly/vk_movies/internal/storage"
 	"github.com/P1coFly/vk_movies/internal/storage/postgresql"
 	"github.com/stretchr/testify/assert"
 )
 
+const testDSN = "host=localhost user=api_service password=12345678 dbname=VK_MOVIES sslmode=disable"
+
+var ctx = context.Background()
+
 func deleteLastActor(storage *postgresql.Storage) error {
-	actors, err := storage.GetActors()
+	actors, err := storage.GetActors(ctx)
 	if err != nil {
 		return err
 	}
@@ -19,11 +32,11 @@ func deleteLastActor(storage *postgresql.Storage) error {
 		return nil
 	}
 	lastActor := actors[len(actors)-1]
-	return storage.DeleteActorByID(lastActor.Id)
+	return storage.DeleteActorByID(ctx, lastActor.Id)
 }
 
 func deleteLastMovie(storage *postgresql.Storage) error {
-	movies, err := storage.GetSortedMovies("id", "ASC")
+	movies, err := storage.GetSortedMovies(ctx, "id", "ASC")
 	if err != nil {
 		return err
 	}
@@ -31,22 +44,22 @@ func deleteLastMovie(storage *postgresql.Storage) error {
 		return nil
 	}
 	lastMovie := movies[len(movies)-1]
-	return storage.DeleteMovieByID(lastMovie.Id)
+	return storage.DeleteMovieByID(ctx, lastMovie.Id)
 }
 
 func TestActor(t *testing.T) {
-	storage, err := postgresql.New("localhost")
+	storage, err := postgresql.New(testDSN)
 	if err != nil {
 		t.Fatal("Error initializing storage:", err)
 	}
 
 	testActor := actor.Actor{Name: "TestActor", Sex: "M", Birthday: "2000-01-01"}
-	err = storage.SaveActor(testActor.Name, testActor.Sex, testActor.Birthday)
+	err = storage.SaveActor(ctx, testActor.Name, testActor.Sex, testActor.Birthday)
 	if err != nil {
 		t.Fatal("Error saving actor:", err)
 	}
 
-	actors, err := storage.GetActors()
+	actors, err := storage.GetActors(ctx)
 	if err != nil {
 		t.Fatal("Error retrieving actors from database:", err)
 	}
@@ -67,18 +80,18 @@ func TestActor(t *testing.T) {
 }
 
 func TestMovie(t *testing.T) {
-	storage, err := postgresql.New("localhost")
+	storage, err := postgresql.New(testDSN)
 	if err != nil {
 		t.Fatal("Error initializing storage:", err)
 	}
 
 	testMovie := movie.Movie{Title: "TestMovie", Description: "TestDescription", DateOfIssue: "2000-01-01", Rating: 7.5}
-	err = storage.SaveMovie(testMovie, []int{1, 2})
+	err = storage.SaveMovie(ctx, testMovie, []int{1, 2}, nil)
 	if err != nil {
 		t.Fatal("Error saving movie:", err)
 	}
 
-	movies, err := storage.GetSortedMovies("title", "ASC")
+	movies, err := storage.GetSortedMovies(ctx, "title", "ASC")
 	if err != nil {
 		t.Fatal("Error retrieving movies from database:", err)
 	}
@@ -97,23 +110,26 @@ func TestMovie(t *testing.T) {
 	}
 
 	assert.True(t, found, "Test movie not found in database")
+
+	_, err = storage.GetSortedMovies(ctx, "title", "sideways")
+	assert.Error(t, err, "invalid sort order")
 }
 
 func TestUpdateActor(t *testing.T) {
-	storage, err := postgresql.New("localhost")
+	storage, err := postgresql.New(testDSN)
 	if err != nil {
 		t.Fatal("Error initializing storage:", err)
 	}
 
 	// Сохранение актера для обновления
 	testActor := actor.Actor{Name: "TestActor", Sex: "M", Birthday: "2000-01-01"}
-	err = storage.SaveActor(testActor.Name, testActor.Sex, testActor.Birthday)
+	err = storage.SaveActor(ctx, testActor.Name, testActor.Sex, testActor.Birthday)
 	if err != nil {
 		t.Fatal("Error saving actor for update:", err)
 	}
 
 	// Получение списка актеров для обновления
-	actorsBeforeUpdate, err := storage.GetActors()
+	actorsBeforeUpdate, err := storage.GetActors(ctx)
 	if err != nil {
 		t.Fatal("Error retrieving actors before update:", err)
 	}
@@ -125,13 +141,13 @@ func TestUpdateActor(t *testing.T) {
 	newName := "UpdatedName"
 	newSex := "F"
 	newBirthday := "1990-05-05T00:00:00Z"
-	err = storage.UpdateActor(actorToUpdate.Id, newName, newSex, newBirthday)
+	err = storage.UpdateActor(ctx, actorToUpdate.Id, newName, newSex, newBirthday)
 	if err != nil {
 		t.Fatal("Error updating actor:", err)
 	}
 
 	// Получение списка актеров после обновления
-	actorsAfterUpdate, err := storage.GetActors()
+	actorsAfterUpdate, err := storage.GetActors(ctx)
 	if err != nil {
 		t.Fatal("Error retrieving actors after update:", err)
 	}
@@ -146,27 +162,27 @@ func TestUpdateActor(t *testing.T) {
 	assert.True(t, found, "Updated actor not found in database")
 
 	// Удаление созданного актера после теста
-	err = storage.DeleteActorByID(actorToUpdate.Id)
+	err = storage.DeleteActorByID(ctx, actorToUpdate.Id)
 	if err != nil {
 		t.Fatal("Error deleting actor after test:", err)
 	}
 }
 
 func TestUpdateMovie(t *testing.T) {
-	storage, err := postgresql.New("localhost")
+	storage, err := postgresql.New(testDSN)
 	if err != nil {
 		t.Fatal("Error initializing storage:", err)
 	}
 
 	// Сохранение фильма для обновления
 	testMovie := movie.Movie{Title: "TestMovie", Description: "TestDescription", DateOfIssue: "2000-01-01", Rating: 7.5}
-	err = storage.SaveMovie(testMovie, []int{1, 2})
+	err = storage.SaveMovie(ctx, testMovie, []int{1, 2}, nil)
 	if err != nil {
 		t.Fatal("Error saving movie for update:", err)
 	}
 
 	// Получение списка фильмов для обновления
-	moviesBeforeUpdate, err := storage.GetSortedMovies("id", "ASC")
+	moviesBeforeUpdate, err := storage.GetSortedMovies(ctx, "id", "ASC")
 	if err != nil {
 		t.Fatal("Error retrieving movies before update:", err)
 	}
@@ -179,13 +195,13 @@ func TestUpdateMovie(t *testing.T) {
 	newDescription := "UpdatedDescription"
 	newDateOfIssue := "2020-12-31T00:00:00Z"
 	var newRating float64 = 8.0
-	err = storage.UpdateMovie(movieToUpdate.Id, newTitle, newDescription, newDateOfIssue, newRating)
+	err = storage.UpdateMovie(ctx, movieToUpdate.Id, newTitle, newDescription, newDateOfIssue, newRating)
 	if err != nil {
 		t.Fatal("Error updating movie:", err)
 	}
 
 	// Получение списка фильмов после обновления
-	moviesAfterUpdate, err := storage.GetSortedMovies("id", "ASC")
+	moviesAfterUpdate, err := storage.GetSortedMovies(ctx, "id", "ASC")
 	if err != nil {
 		t.Fatal("Error retrieving movies after update:", err)
 	}
@@ -201,8 +217,490 @@ func TestUpdateMovie(t *testing.T) {
 	assert.True(t, found, "Updated movie not found in database")
 
 	// Удаление созданного фильма после теста
-	err = storage.DeleteMovieByID(movieToUpdate.Id)
+	err = storage.DeleteMovieByID(ctx, movieToUpdate.Id)
 	if err != nil {
 		t.Fatal("Error deleting movie after test:", err)
 	}
 }
+
+func TestSearchMovies(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	testMovie := movie.Movie{Title: "Космические путешествия", Description: "Экипаж летит к далёким звёздам", DateOfIssue: "2000-01-01", Rating: 7.5}
+	err = storage.SaveMovie(ctx, testMovie, nil, nil)
+	if err != nil {
+		t.Fatal("Error saving movie:", err)
+	}
+	defer deleteLastMovie(storage)
+
+	// Другая словоформа находит фильм благодаря стеммингу
+	results, err := storage.SearchMovies(ctx, "путешествие экипажи", 10)
+	if err != nil {
+		t.Fatal("Error searching movies:", err)
+	}
+
+	if assert.NotEmpty(t, results) {
+		assert.Equal(t, testMovie.Title, results[0].Title)
+		assert.Contains(t, results[0].Headline, "<b>путешествия</b>")
+		assert.Contains(t, results[0].Snippet, "<b>Экипаж</b>")
+	}
+}
+
+func TestFindMoviesByActorNameFragmentFuzzy(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	err = storage.SaveActor(ctx, "Леонардо ДиКаприо", "M", "1974-11-11")
+	if err != nil {
+		t.Fatal("Error saving actor:", err)
+	}
+	defer deleteLastActor(storage)
+	actorID, err := storage.FindActorID(ctx, "Леонардо ДиКаприо", "1974-11-11")
+	if err != nil {
+		t.Fatal("Error finding actor:", err)
+	}
+
+	testMovie := movie.Movie{Title: "FuzzyTestMovie", Description: "TestDescription", DateOfIssue: "2000-01-01", Rating: 7.5}
+	err = storage.SaveMovie(ctx, testMovie, []int{int(actorID)}, nil)
+	if err != nil {
+		t.Fatal("Error saving movie:", err)
+	}
+	defer deleteLastMovie(storage)
+
+	for _, fragment := range []string{"Ди Каприо", "Leonardo", "ДиКапрео", "dicaprio"} {
+		movies, err := storage.FindMoviesByActorNameFragment(ctx, fragment, 0.4)
+		if err != nil {
+			t.Fatal("Error searching movies:", err)
+		}
+
+		var found bool
+		for _, m := range movies {
+			if m.Title == testMovie.Title {
+				found = true
+				assert.Equal(t, []movie.MatchedActor{{Id: actorID, Name: "Леонардо ДиКаприо"}}, m.MatchedActors)
+				break
+			}
+		}
+		assert.True(t, found, "Test movie not found by %q", fragment)
+	}
+}
+
+func TestFindMoviesByActorNameFragmentDistinct(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	var actorIDs []int
+	for _, name := range []string{"Брэд Питт", "Брэд Дуриф"} {
+		if err := storage.SaveActor(ctx, name, "M", "1963-12-18"); err != nil {
+			t.Fatal("Error saving actor:", err)
+		}
+		defer deleteLastActor(storage)
+		id, err := storage.FindActorID(ctx, name, "1963-12-18")
+		if err != nil {
+			t.Fatal("Error finding actor:", err)
+		}
+		actorIDs = append(actorIDs, int(id))
+	}
+
+	testMovie := movie.Movie{Title: "DistinctTestMovie", Description: "TestDescription", DateOfIssue: "2000-01-01", Rating: 7.5}
+	err = storage.SaveMovie(ctx, testMovie, actorIDs, nil)
+	if err != nil {
+		t.Fatal("Error saving movie:", err)
+	}
+	defer deleteLastMovie(storage)
+
+	movies, err := storage.FindMoviesByActorNameFragment(ctx, "Брэд", 0.4)
+	if err != nil {
+		t.Fatal("Error searching movies:", err)
+	}
+
+	var found []movie.ActorMatch
+	for _, m := range movies {
+		if m.Title == testMovie.Title {
+			found = append(found, m)
+		}
+	}
+	if assert.Len(t, found, 1, "movie must be returned once") {
+		assert.Len(t, found[0].MatchedActors, 2)
+	}
+}
+
+func TestFindMovies(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	testMovie := movie.Movie{Title: "FilterTestMovie 50%", Description: "TestDescription", DateOfIssue: "1999-03-31", Rating: 8.7}
+	err = storage.SaveMovie(ctx, testMovie, nil, nil)
+	if err != nil {
+		t.Fatal("Error saving movie:", err)
+	}
+	defer deleteLastMovie(storage)
+
+	ratingMin, ratingMax := 8.5, 9.0
+	movies, total, err := storage.FindMovies(ctx, movie.Filter{
+		Title:     "testmovie 50%",
+		DateFrom:  "1999-01-01",
+		DateTo:    "1999-12-31",
+		RatingMin: &ratingMin,
+		RatingMax: &ratingMax,
+		Sort:      movie.SortByRating,
+		Desc:      true,
+		Limit:     10,
+	})
+	if err != nil {
+		t.Fatal("Error finding movies:", err)
+	}
+	if assert.Len(t, movies, 1) {
+		assert.Equal(t, testMovie.Title, movies[0].Title)
+	}
+	assert.Equal(t, 1, total)
+
+	// Все условия объединяются через AND
+	ratingMin = 9.5
+	movies, total, err = storage.FindMovies(ctx, movie.Filter{Title: "FilterTestMovie", RatingMin: &ratingMin})
+	if err != nil {
+		t.Fatal("Error finding movies:", err)
+	}
+	assert.Empty(t, movies)
+	assert.Zero(t, total)
+}
+
+func TestSuggest(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	err = storage.SaveMovie(ctx, movie.Movie{Title: "Suggesttest_movie", Description: "TestDescription", DateOfIssue: "2000-01-01", Rating: 7.5}, nil, nil)
+	if err != nil {
+		t.Fatal("Error saving movie:", err)
+	}
+	defer deleteLastMovie(storage)
+	err = storage.SaveActor(ctx, "SuggestTest Actor", "M", "2000-01-01")
+	if err != nil {
+		t.Fatal("Error saving actor:", err)
+	}
+	defer deleteLastActor(storage)
+
+	suggestions, err := storage.Suggest(ctx, "suggesttest", 10)
+	if err != nil {
+		t.Fatal("Error getting suggestions:", err)
+	}
+	if assert.Len(t, suggestions, 2) {
+		assert.Equal(t, suggestion.KindMovie, suggestions[0].Kind)
+		assert.Equal(t, suggestion.KindActor, suggestions[1].Kind)
+	}
+
+	// _ в префиксе не работает как шаблон LIKE
+	suggestions, err = storage.Suggest(ctx, "suggesttest_", 10)
+	if err != nil {
+		t.Fatal("Error getting suggestions:", err)
+	}
+	if assert.Len(t, suggestions, 1) {
+		assert.Equal(t, "Suggesttest_movie", suggestions[0].Text)
+	}
+}
+
+func TestGenres(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	dramaID, err := storage.SaveGenre(ctx, genre.Genre{Name: "GenreTestDrama"})
+	if err != nil {
+		t.Fatal("Error saving genre:", err)
+	}
+	defer storage.DeleteGenreByID(ctx, dramaID)
+	crimeID, err := storage.SaveGenre(ctx, genre.Genre{Name: "GenreTestCrime"})
+	if err != nil {
+		t.Fatal("Error saving genre:", err)
+	}
+	defer storage.DeleteGenreByID(ctx, crimeID)
+
+	_, err = storage.SaveGenre(ctx, genre.Genre{Name: "genretestdrama"})
+	assert.ErrorIs(t, err, pkgstorage.ErrGenreExists)
+
+	err = storage.SaveMovie(ctx, movie.Movie{Title: "GenreTestMovie", Description: "TestDescription", DateOfIssue: "2000-01-01", Rating: 7.5},
+		nil, []int64{dramaID, crimeID})
+	if err != nil {
+		t.Fatal("Error saving movie:", err)
+	}
+	defer deleteLastMovie(storage)
+
+	// Фильм должен иметь все жанры фильтра
+	movies, total, err := storage.FindMovies(ctx, movie.Filter{GenreIDs: []int64{dramaID, crimeID, dramaID}})
+	if err != nil {
+		t.Fatal("Error finding movies:", err)
+	}
+	if assert.Len(t, movies, 1) {
+		assert.Equal(t, 1, total)
+		assert.Len(t, movies[0].Genres, 2)
+	}
+
+	movieID := movies[0].Id
+	err = storage.SetMovieGenres(ctx, movieID, []int64{dramaID})
+	if err != nil {
+		t.Fatal("Error setting movie genres:", err)
+	}
+	movies, _, err = storage.FindMovies(ctx, movie.Filter{GenreIDs: []int64{dramaID, crimeID}})
+	if err != nil {
+		t.Fatal("Error finding movies:", err)
+	}
+	assert.Empty(t, movies)
+
+	// Неизвестный жанр откатывает и обновление фильма
+	err = storage.UpdateMovieWithGenres(ctx, movieID, "GenreTestRenamed", "", "", 0, []int64{0})
+	assert.ErrorIs(t, err, pkgstorage.ErrGenreNotFound)
+	m, err := storage.GetMovieByID(ctx, movieID)
+	if err != nil {
+		t.Fatal("Error getting movie:", err)
+	}
+	assert.NotEqual(t, "GenreTestRenamed", m.Title)
+
+	err = storage.SaveMovie(ctx, movie.Movie{Title: "GenreTestMovie", Description: "TestDescription", DateOfIssue: "2000-01-01", Rating: 7.5},
+		nil, []int64{0})
+	assert.ErrorIs(t, err, pkgstorage.ErrGenreNotFound)
+}
+
+func TestCredits(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	err = storage.SaveActor(ctx, "CreditsTest Director", "F", "1965-06-21")
+	if err != nil {
+		t.Fatal("Error saving actor:", err)
+	}
+	defer deleteLastActor(storage)
+	actors, err := storage.GetActors(ctx)
+	if err != nil {
+		t.Fatal("Error retrieving actors from database:", err)
+	}
+	personID := actors[len(actors)-1].Id
+
+	err = storage.SaveMovie(ctx, movie.Movie{Title: "CreditsTestMovie", Description: "TestDescription", DateOfIssue: "1999-03-31", Rating: 8.7}, nil, nil)
+	if err != nil {
+		t.Fatal("Error saving movie:", err)
+	}
+	defer deleteLastMovie(storage)
+	movies, _, err := storage.FindMovies(ctx, movie.Filter{Title: "CreditsTestMovie"})
+	if err != nil || len(movies) != 1 {
+		t.Fatal("Error finding movie:", err)
+	}
+	movieID := movies[0].Id
+
+	// Один человек может быть в фильме и режиссёром, и актёром
+	err = storage.SetMovieCredits(ctx, movieID, []credit.Credit{
+		{PersonID: personID, Role: credit.RoleDirector},
+		{PersonID: personID, Role: credit.RoleActor, CharacterName: "Тринити", BillingOrder: 1},
+	})
+	if err != nil {
+		t.Fatal("Error setting credits:", err)
+	}
+
+	m, err := storage.GetMovieByID(ctx, movieID)
+	if err != nil {
+		t.Fatal("Error getting movie:", err)
+	}
+	if assert.Len(t, m.Credits, 2) {
+		assert.Equal(t, credit.RoleActor, m.Credits[0].Role)
+		assert.Equal(t, "Тринити", m.Credits[0].CharacterName)
+		assert.Equal(t, 1, m.Credits[0].BillingOrder)
+		assert.Equal(t, credit.RoleDirector, m.Credits[1].Role)
+	}
+
+	movies, _, err = storage.FindMovies(ctx, movie.Filter{Director: "creditstest"})
+	if err != nil {
+		t.Fatal("Error finding movies:", err)
+	}
+	assert.Len(t, movies, 1)
+
+	a, err := storage.GetActorByID(ctx, personID)
+	if err != nil {
+		t.Fatal("Error getting actor:", err)
+	}
+	assert.Len(t, a.Credits, 2)
+
+	filmography, err := storage.GetMoviesByActorID(ctx, personID)
+	if err != nil {
+		t.Fatal("Error getting filmography:", err)
+	}
+	if assert.Len(t, filmography, 1) {
+		assert.Equal(t, "Тринити", filmography[0].CharacterName)
+		assert.Equal(t, 1, filmography[0].BillingOrder)
+	}
+
+	err = storage.SetMovieCredits(ctx, movieID, []credit.Credit{{PersonID: 0, Role: credit.RoleWriter}})
+	assert.ErrorIs(t, err, pkgstorage.ErrActorNotFound)
+}
+
+func TestCastBillingOrder(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	var actorIDs []int
+	for _, name := range []string{"BillingTest Lead", "BillingTest Support"} {
+		if err := storage.SaveActor(ctx, name, "M", "1970-01-01"); err != nil {
+			t.Fatal("Error saving actor:", err)
+		}
+		defer deleteLastActor(storage)
+		actors, err := storage.GetActors(ctx)
+		if err != nil {
+			t.Fatal("Error retrieving actors from database:", err)
+		}
+		actorIDs = append(actorIDs, int(actors[len(actors)-1].Id))
+	}
+
+	// Актёры из actorIDs идут в титрах в порядке перечисления
+	err = storage.SaveMovie(ctx, movie.Movie{Title: "BillingTestMovie", Description: "TestDescription", DateOfIssue: "1999-03-31", Rating: 8.7},
+		[]int{actorIDs[1], actorIDs[0]}, nil)
+	if err != nil {
+		t.Fatal("Error saving movie:", err)
+	}
+	defer deleteLastMovie(storage)
+	movies, _, err := storage.FindMovies(ctx, movie.Filter{Title: "BillingTestMovie"})
+	if err != nil || len(movies) != 1 {
+		t.Fatal("Error finding movie:", err)
+	}
+
+	m, err := storage.GetMovieByID(ctx, movies[0].Id)
+	if err != nil {
+		t.Fatal("Error getting movie:", err)
+	}
+	if assert.Len(t, m.Credits, 2) {
+		assert.Equal(t, "BillingTest Support", m.Credits[0].PersonName)
+		assert.Equal(t, 1, m.Credits[0].BillingOrder)
+		assert.Equal(t, "BillingTest Lead", m.Credits[1].PersonName)
+		assert.Equal(t, 2, m.Credits[1].BillingOrder)
+	}
+}
+
+func TestReviews(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	// Пользователь создаётся один раз и переиспользуется следующими запусками
+	u, err := user.New("reviewtest", "reviewtest-password", user.RoleUser)
+	if err != nil {
+		t.Fatal("Error creating user:", err)
+	}
+	userID, err := storage.SaveUser(ctx, *u)
+	if errors.Is(err, pkgstorage.ErrUserExists) {
+		var existing user.User
+		existing, err = storage.GetUserByLogin(ctx, u.Login)
+		userID = existing.Id
+	}
+	if err != nil {
+		t.Fatal("Error saving user:", err)
+	}
+
+	err = storage.SaveMovie(ctx, movie.Movie{Title: "ReviewTestMovie", Description: "TestDescription", DateOfIssue: "2000-01-01", Rating: 5}, nil, nil)
+	if err != nil {
+		t.Fatal("Error saving movie:", err)
+	}
+	defer deleteLastMovie(storage)
+	movies, _, err := storage.FindMovies(ctx, movie.Filter{Title: "ReviewTestMovie"})
+	if err != nil || len(movies) != 1 {
+		t.Fatal("Error finding movie:", err)
+	}
+	movieID := movies[0].Id
+	assert.Nil(t, movies[0].UserRating)
+
+	saved, err := storage.SaveReview(ctx, review.Review{MovieID: movieID, UserID: userID, Score: 8, Text: "Хорошо"})
+	if err != nil {
+		t.Fatal("Error saving review:", err)
+	}
+	assert.Equal(t, "reviewtest", saved.Login)
+
+	_, err = storage.SaveReview(ctx, review.Review{MovieID: movieID, UserID: userID, Score: 3})
+	assert.ErrorIs(t, err, pkgstorage.ErrReviewExists)
+
+	err = storage.UpdateReview(ctx, saved.Id, 7, "Неплохо")
+	if err != nil {
+		t.Fatal("Error updating review:", err)
+	}
+
+	m, err := storage.GetMovieByID(ctx, movieID)
+	if err != nil {
+		t.Fatal("Error getting movie:", err)
+	}
+	if assert.NotNil(t, m.UserRating) {
+		assert.Equal(t, 7.0, *m.UserRating)
+	}
+	assert.Equal(t, 1, m.Votes)
+	assert.Equal(t, 5.0, m.Rating)
+
+	reviews, err := storage.GetMovieReviews(ctx, movieID, 10, 0)
+	if err != nil {
+		t.Fatal("Error getting reviews:", err)
+	}
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, "Неплохо", reviews[0].Text)
+	}
+
+	err = storage.DeleteReviewByID(ctx, saved.Id)
+	if err != nil {
+		t.Fatal("Error deleting review:", err)
+	}
+	_, err = storage.GetReviewByID(ctx, saved.Id)
+	assert.ErrorIs(t, err, pkgstorage.ErrReviewNotFound)
+}
+
+func TestUpdateUserPasswordRevokesSessions(t *testing.T) {
+	storage, err := postgresql.New(testDSN)
+	if err != nil {
+		t.Fatal("Error initializing storage:", err)
+	}
+
+	u, err := user.New("passwordtest", "passwordtest-password", user.RoleUser)
+	if err != nil {
+		t.Fatal("Error creating user:", err)
+	}
+	userID, err := storage.SaveUser(ctx, *u)
+	if errors.Is(err, pkgstorage.ErrUserExists) {
+		var existing user.User
+		existing, err = storage.GetUserByLogin(ctx, u.Login)
+		userID = existing.Id
+	}
+	if err != nil {
+		t.Fatal("Error saving user:", err)
+	}
+
+	// Две сессии: пароль меняется из первой, вторая могла быть украдена
+	issuedAt := time.Now().Add(-time.Minute)
+	expiresAt := time.Now().Add(time.Hour)
+	err = storage.UpdateUserPassword(ctx, userID, u.PasswordHash, "passwordtest-session-1", expiresAt)
+	if err != nil {
+		t.Fatal("Error updating password:", err)
+	}
+
+	for _, jti := range []string{"passwordtest-session-1", "passwordtest-session-2"} {
+		revoked, err := storage.IsAccessTokenRevoked(ctx, jti, userID, issuedAt)
+		if err != nil {
+			t.Fatal("Error checking token:", err)
+		}
+		assert.True(t, revoked, jti)
+	}
+
+	// Токены, выданные после смены пароля, действуют
+	revoked, err := storage.IsAccessTokenRevoked(ctx, "passwordtest-session-3", userID, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatal("Error checking token:", err)
+	}
+	assert.False(t, revoked)
+}
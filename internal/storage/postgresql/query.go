@@ -0,0 +1,45 @@
+package postgresql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// sqlQuery collects the conditions and arguments of a statement built from parts.
+// SQL is only ever written in code: values are added with arg and reach the
+// database as numbered parameters, never as part of the SQL text.
+type sqlQuery struct {
+	conds []string
+	args  []any
+}
+
+// arg adds a value and returns its placeholder, e.g. $3.
+func (q *sqlQuery) arg(v any) string {
+	q.args = append(q.args, v)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+// where adds a condition, the conditions are joined with AND.
+func (q *sqlQuery) where(cond string) {
+	q.conds = append(q.conds, cond)
+}
+
+// whereSQL returns the WHERE clause, empty if there are no conditions.
+func (q *sqlQuery) whereSQL() string {
+	if len(q.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conds, " AND ")
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// contains returns the ILIKE pattern matching strings that contain s literally.
+func contains(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// startsWith returns the LIKE pattern matching strings that start with s literally.
+func startsWith(s string) string {
+	return likeEscaper.Replace(s) + "%"
+}
@@ -0,0 +1,18 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLQuery(t *testing.T) {
+	q := &sqlQuery{}
+	assert.Empty(t, q.whereSQL())
+
+	q.where("m.title ILIKE " + q.arg(contains("50%_off")))
+	q.where("m.rating >= " + q.arg(7.5))
+
+	assert.Equal(t, " WHERE m.title ILIKE $1 AND m.rating >= $2", q.whereSQL())
+	assert.Equal(t, []any{`%50\%\_off%`, 7.5}, q.args)
+}
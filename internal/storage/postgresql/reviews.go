@@ -0,0 +1,192 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/P1coFly/vk_movies/internal/models/movie"
+	"github.com/P1coFly/vk_movies/internal/models/review"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/lib/pq"
+)
+
+const reviewColumns = `r.id, r.movie_id, r.user_id, u.login, r.score, r.text, r.created_at, r.updated_at`
+
+// SaveReview saves the review of the user and returns it as stored. A second review of the
+// same movie returns storage.ErrReviewExists, an unknown movie storage.ErrMovieNotFound.
+func (s *Storage) SaveReview(ctx context.Context, r review.Review) (review.Review, error) {
+	const op = "storage.postgresql.SaveReview"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	saved, err := scanReview(s.db.QueryRowContext(ctx, `WITH r AS (
+			INSERT INTO public."REVIEWS" (movie_id, user_id, score, text) VALUES ($1, $2, $3, $4) RETURNING *
+		)
+		SELECT `+reviewColumns+` FROM r JOIN public."USERS" u ON u.id = r.user_id`,
+		r.MovieID, r.UserID, r.Score, r.Text))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			switch {
+			case pqErr.Code == uniqueViolation:
+				return saved, fmt.Errorf("%s: %w", op, storage.ErrReviewExists)
+			case pqErr.Code == foreignKeyViolation && pqErr.Constraint == "REVIEWS_movie_id_fkey":
+				return saved, fmt.Errorf("%s: %w", op, storage.ErrMovieNotFound)
+			}
+		}
+		return saved, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, nil
+}
+
+func (s *Storage) GetReviewByID(ctx context.Context, reviewID int64) (review.Review, error) {
+	const op = "storage.postgresql.GetReviewByID"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	r, err := scanReview(s.db.QueryRowContext(ctx, `SELECT `+reviewColumns+`
+		FROM public."REVIEWS" r JOIN public."USERS" u ON u.id = r.user_id WHERE r.id = $1`, reviewID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return r, fmt.Errorf("%s: %w", op, storage.ErrReviewNotFound)
+	}
+	if err != nil {
+		return r, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return r, nil
+}
+
+// GetMovieReviews returns a page of the reviews of the movie, the latest first.
+func (s *Storage) GetMovieReviews(ctx context.Context, movieID int64, limit, offset int) ([]review.Review, error) {
+	const op = "storage.postgresql.GetMovieReviews"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM public."MOVIES" WHERE id = $1)`, movieID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrMovieNotFound)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+reviewColumns+`
+		FROM public."REVIEWS" r JOIN public."USERS" u ON u.id = r.user_id
+		WHERE r.movie_id = $1
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT $2 OFFSET $3`, movieID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	reviews := []review.Review{}
+	for rows.Next() {
+		r, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		reviews = append(reviews, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reviews, nil
+}
+
+func (s *Storage) UpdateReview(ctx context.Context, reviewID int64, newScore int, newText string) error {
+	const op = "storage.postgresql.UpdateReview"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE public."REVIEWS" SET score = $1, text = $2, updated_at = now() WHERE id = $3`,
+		newScore, newText, reviewID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrReviewNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteReviewByID(ctx context.Context, reviewID int64) error {
+	const op = "storage.postgresql.DeleteReviewByID"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM public."REVIEWS" WHERE id = $1`, reviewID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrReviewNotFound)
+	}
+
+	return nil
+}
+
+func scanReview(row scanner) (review.Review, error) {
+	var r review.Review
+	err := row.Scan(&r.Id, &r.MovieID, &r.UserID, &r.Login, &r.Score, &r.Text, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+// withReviewStats fills in the average user score and the number of reviews of the movies with one query.
+func (s *Storage) withReviewStats(ctx context.Context, movies []movie.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(movies))
+	for i, m := range movies {
+		ids[i] = m.Id
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT movie_id, round(avg(score), 1)::float8, count(*)
+		FROM public."REVIEWS" WHERE movie_id = ANY($1) GROUP BY movie_id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type stats struct {
+		avg   float64
+		votes int
+	}
+	byMovie := make(map[int64]stats)
+	for rows.Next() {
+		var movieID int64
+		var st stats
+		if err := rows.Scan(&movieID, &st.avg, &st.votes); err != nil {
+			return err
+		}
+		byMovie[movieID] = st
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range movies {
+		if st, ok := byMovie[movies[i].Id]; ok {
+			movies[i].UserRating = &st.avg
+			movies[i].Votes = st.votes
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlight(t *testing.T) {
+	headline := "Фильм " + headlineStart + "Tom & Jerry" + headlineStop + " <script>"
+
+	assert.Equal(t, "Фильм <b>Tom &amp; Jerry</b> &lt;script&gt;", highlight(headline))
+}
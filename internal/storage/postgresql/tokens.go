@@ -0,0 +1,139 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/logger"
+	"github.com/P1coFly/vk_movies/internal/storage"
+)
+
+// SaveRefreshToken stores the hash of a refresh token issued in the rotation family.
+func (s *Storage) SaveRefreshToken(ctx context.Context, tokenHash []byte, userID int64, familyID string, expiresAt time.Time) error {
+	const op = "storage.postgresql.SaveRefreshToken"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO public."REFRESH_TOKENS" (token_hash, user_id, family_id, expires_at) VALUES ($1, $2, $3, $4)`,
+		tokenHash, userID, familyID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseRefreshToken marks an active refresh token as used and returns its owner and family.
+// Presenting a token that was already used revokes the whole family and returns storage.ErrTokenReused.
+func (s *Storage) UseRefreshToken(ctx context.Context, tokenHash []byte) (int64, string, error) {
+	const op = "storage.postgresql.UseRefreshToken"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	var userID int64
+	var familyID string
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE public."REFRESH_TOKENS" SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > now()
+		RETURNING user_id, family_id
+	`, tokenHash).Scan(&userID, &familyID)
+	if err == nil {
+		return userID, familyID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Токен не активен: проверяем, не пытаются ли использовать его повторно
+	var used bool
+	err = s.db.QueryRowContext(ctx, `SELECT family_id, used_at IS NOT NULL FROM public."REFRESH_TOKENS" WHERE token_hash = $1`,
+		tokenHash).Scan(&familyID, &used)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !used) {
+		return 0, "", fmt.Errorf("%s: %w", op, storage.ErrTokenNotFound)
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.RevokeRefreshFamily(ctx, familyID); err != nil {
+		return 0, "", fmt.Errorf("%s: %w", op, err)
+	}
+	logger.FromContext(ctx).Warn("refresh token reused, token family revoked", "op", op, "family_id", familyID)
+
+	return 0, "", fmt.Errorf("%s: %w", op, storage.ErrTokenReused)
+}
+
+// RevokeRefreshFamily revokes every refresh token of the rotation family.
+func (s *Storage) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	const op = "storage.postgresql.RevokeRefreshFamily"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	_, err := s.db.ExecContext(ctx, `UPDATE public."REFRESH_TOKENS" SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokeRefreshToken revokes the family of the refresh token if it belongs to the user.
+func (s *Storage) RevokeRefreshToken(ctx context.Context, tokenHash []byte, userID int64) error {
+	const op = "storage.postgresql.RevokeRefreshToken"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE public."REFRESH_TOKENS" SET revoked_at = now()
+		WHERE revoked_at IS NULL AND family_id = (
+			SELECT family_id FROM public."REFRESH_TOKENS" WHERE token_hash = $1 AND user_id = $2
+		)
+	`, tokenHash, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokeAccessToken adds the access token id to the revocation list until the token expires.
+func (s *Storage) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	const op = "storage.postgresql.RevokeAccessToken"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO public."REVOKED_TOKENS" (jti, expires_at) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		jti, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Истёкшие токены и так недействительны, держать их в списке незачем
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM public."REVOKED_TOKENS" WHERE expires_at < now()`); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether the access token id is in the revocation list or
+// the token was issued to the user before all of their tokens were revoked.
+func (s *Storage) IsAccessTokenRevoked(ctx context.Context, jti string, userID int64, issuedAt time.Time) (bool, error) {
+	const op = "storage.postgresql.IsAccessTokenRevoked"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	var revoked bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM public."REVOKED_TOKENS" WHERE jti = $1)
+			OR EXISTS (SELECT 1 FROM public."USERS" WHERE id = $2 AND tokens_valid_after > $3)
+	`, jti, userID, issuedAt).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revoked, nil
+}
@@ -0,0 +1,186 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/P1coFly/vk_movies/internal/storage/postgresql")
+
+// rowsKey is the number of rows a statement returned or changed.
+const rowsKey = attribute.Key("db.rows")
+
+// observe starts the span of the storage call op, e.g. storage.postgresql.GetActors.
+// The returned function ends it and records the duration of the call:
+//
+//	ctx, end := observe(ctx, op)
+//	defer end()
+func observe(ctx context.Context, op string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, op)
+	return ctx, func() {
+		span.End()
+		metrics.ObserveQuery(op, start)
+	}
+}
+
+// tracedDB runs every statement in a span of its own with the SQL text and the number of rows.
+// Arguments are never recorded, they may hold password hashes and tokens.
+type tracedDB struct {
+	*sql.DB
+}
+
+func (db tracedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return execContext(ctx, db.DB, query, args...)
+}
+
+func (db tracedDB) QueryContext(ctx context.Context, query string, args ...any) (*tracedRows, error) {
+	return queryContext(ctx, db.DB, query, args...)
+}
+
+func (db tracedDB) QueryRowContext(ctx context.Context, query string, args ...any) *tracedRow {
+	return queryRowContext(ctx, db.DB, query, args...)
+}
+
+func (db tracedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*tracedTx, error) {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{Tx: tx}, nil
+}
+
+// tracedTx is a transaction traced like tracedDB.
+type tracedTx struct {
+	*sql.Tx
+}
+
+func (tx *tracedTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return execContext(ctx, tx.Tx, query, args...)
+}
+
+func (tx *tracedTx) QueryContext(ctx context.Context, query string, args ...any) (*tracedRows, error) {
+	return queryContext(ctx, tx.Tx, query, args...)
+}
+
+func (tx *tracedTx) QueryRowContext(ctx context.Context, query string, args ...any) *tracedRow {
+	return queryRowContext(ctx, tx.Tx, query, args...)
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func execContext(ctx context.Context, q queryer, query string, args ...any) (sql.Result, error) {
+	ctx, span := startStatement(ctx, query)
+	defer span.End()
+
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err == nil {
+		span.SetAttributes(rowsKey.Int64(n))
+	}
+	return result, nil
+}
+
+func queryContext(ctx context.Context, q queryer, query string, args ...any) (*tracedRows, error) {
+	ctx, span := startStatement(ctx, query)
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		recordError(span, err)
+		span.End()
+		return nil, err
+	}
+	return &tracedRows{Rows: rows, span: span}, nil
+}
+
+func queryRowContext(ctx context.Context, q queryer, query string, args ...any) *tracedRow {
+	ctx, span := startStatement(ctx, query)
+	return &tracedRow{row: q.QueryRowContext(ctx, query, args...), span: span}
+}
+
+// tracedRows counts the rows read, the span ends when the rows are closed.
+type tracedRows struct {
+	*sql.Rows
+	span trace.Span
+	n    int64
+}
+
+func (r *tracedRows) Next() bool {
+	if !r.Rows.Next() {
+		return false
+	}
+	r.n++
+	return true
+}
+
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	if err == nil {
+		err = r.Rows.Err()
+	}
+	if err != nil {
+		recordError(r.span, err)
+	}
+	r.span.SetAttributes(rowsKey.Int64(r.n))
+	r.span.End()
+	return err
+}
+
+// tracedRow ends the span when the row is scanned.
+type tracedRow struct {
+	row  *sql.Row
+	span trace.Span
+}
+
+func (r *tracedRow) Scan(dest ...any) error {
+	defer r.span.End()
+
+	err := r.row.Scan(dest...)
+	switch {
+	case err == nil:
+		r.span.SetAttributes(rowsKey.Int64(1))
+	case errors.Is(err, sql.ErrNoRows):
+		r.span.SetAttributes(rowsKey.Int64(0))
+	default:
+		recordError(r.span, err)
+	}
+	return err
+}
+
+func startStatement(ctx context.Context, query string) (context.Context, trace.Span) {
+	query = strings.TrimSpace(query)
+	operation, _, _ := strings.Cut(query, " ")
+	operation = strings.ToUpper(strings.TrimSpace(operation))
+
+	return tracer.Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+		),
+	)
+}
+
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
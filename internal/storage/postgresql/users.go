@@ -0,0 +1,118 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/P1coFly/vk_movies/internal/models/user"
+	"github.com/P1coFly/vk_movies/internal/storage"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the PostgreSQL error code of a unique constraint violation.
+const uniqueViolation = "23505"
+
+func (s *Storage) SaveUser(ctx context.Context, u user.User) (int64, error) {
+	const op = "storage.postgresql.SaveUser"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `INSERT INTO public."USERS" (login, password_hash, role) VALUES ($1, $2, $3) RETURNING id`,
+		u.Login, u.PasswordHash, u.Role).Scan(&id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s *Storage) GetUserByLogin(ctx context.Context, login string) (user.User, error) {
+	const op = "storage.postgresql.GetUserByLogin"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	u, err := scanUser(s.db.QueryRowContext(ctx, `SELECT id, login, password_hash, role, created_at FROM public."USERS" WHERE login = $1`, login))
+	if err != nil {
+		return u, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return u, nil
+}
+
+func (s *Storage) GetUserByID(ctx context.Context, userID int64) (user.User, error) {
+	const op = "storage.postgresql.GetUserByID"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	u, err := scanUser(s.db.QueryRowContext(ctx, `SELECT id, login, password_hash, role, created_at FROM public."USERS" WHERE id = $1`, userID))
+	if err != nil {
+		return u, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return u, nil
+}
+
+// UpdateUserPassword sets the new password hash and, in the same transaction, revokes every
+// refresh token of the user and every access token issued before, so that stolen tokens stop
+// working. Token issue times have a precision of a second, so the access token jti of the
+// caller is revoked explicitly; an empty jti is skipped.
+func (s *Storage) UpdateUserPassword(ctx context.Context, userID int64, passwordHash string, jti string, jtiExpiresAt time.Time) error {
+	const op = "storage.postgresql.UpdateUserPassword"
+	ctx, end := observe(ctx, op)
+	defer end()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE public."USERS" SET password_hash = $1, tokens_valid_after = date_trunc('second', now()) WHERE id = $2`, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE public."REFRESH_TOKENS" SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if jti != "" {
+		_, err = tx.ExecContext(ctx, `INSERT INTO public."REVOKED_TOKENS" (jti, expires_at) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			jti, jtiExpiresAt)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func scanUser(row scanner) (user.User, error) {
+	var u user.User
+	err := row.Scan(&u.Id, &u.Login, &u.PasswordHash, &u.Role, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return u, storage.ErrUserNotFound
+	}
+
+	return u, err
+}
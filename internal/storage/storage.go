@@ -3,6 +3,15 @@ package storage
 import "errors"
 
 var (
-	ErrActorNotFound = errors.New("actor not found")
-	ErrMovieNotFound = errors.New("movie not found")
+	ErrActorNotFound  = errors.New("actor not found")
+	ErrMovieNotFound  = errors.New("movie not found")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrUserExists     = errors.New("user already exists")
+	ErrTokenNotFound  = errors.New("token not found")
+	ErrTokenReused    = errors.New("refresh token reused")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrGenreNotFound  = errors.New("genre not found")
+	ErrGenreExists    = errors.New("genre already exists")
+	ErrReviewNotFound = errors.New("review not found")
+	ErrReviewExists   = errors.New("review already exists")
 )
@@ -0,0 +1,60 @@
+// Package tracing sets up OpenTelemetry: the tracer provider spans are exported with
+// and W3C trace context propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/P1coFly/vk_movies/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Setup installs the global tracer provider and propagator. The returned function
+// flushes the spans not yet exported and must be called before the process exits.
+func Setup(ctx context.Context, cfg config.Tracing) (func(context.Context) error, error) {
+	const op = "tracing.Setup"
+
+	// traceparent и tracestate входящих запросов продолжают трассу клиента
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.TracingServiceName))),
+	}
+
+	switch cfg.TracingExporter {
+	case config.TracingExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		// Локально спаны нужны сразу, а не пачкой
+		opts = append(opts, sdktrace.WithSyncer(exporter))
+	case config.TracingExporterOTLP:
+		clientOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.TracingOTLPEndpoint)}
+		if cfg.TracingOTLPInsecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,85 @@
+// Package translit transliterates names between the Cyrillic and Latin alphabets,
+// so that "Леонардо" finds "Leonardo" and the other way round.
+package translit
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+var toLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// toCyrillic lists Latin letter combinations, longer ones first so that "sh" wins over "s".
+var toCyrillic = []struct{ latin, cyrillic string }{
+	{"shch", "щ"},
+	{"sch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"ya", "я"}, {"yo", "ё"}, {"ye", "е"}, {"ph", "ф"}, {"th", "т"},
+	{"a", "а"}, {"b", "б"}, {"c", "к"}, {"d", "д"}, {"e", "е"}, {"f", "ф"}, {"g", "г"},
+	{"h", "х"}, {"i", "и"}, {"j", "дж"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"},
+	{"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"}, {"s", "с"}, {"t", "т"}, {"u", "у"},
+	{"v", "в"}, {"w", "в"}, {"x", "кс"}, {"y", "й"}, {"z", "з"},
+}
+
+// ToLatin transliterates Cyrillic letters of s, the result is lower case.
+func ToLatin(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if l, ok := toLatin[r]; ok {
+			b.WriteString(l)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ToCyrillic transliterates Latin letters of s, the result is lower case.
+func ToCyrillic(s string) string {
+	s = strings.ToLower(s)
+
+	var b strings.Builder
+next:
+	for len(s) > 0 {
+		for _, t := range toCyrillic {
+			if strings.HasPrefix(s, t.latin) {
+				b.WriteString(t.cyrillic)
+				s = s[len(t.latin):]
+				continue next
+			}
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		b.WriteRune(r)
+		s = s[size:]
+	}
+	return b.String()
+}
+
+// Variants returns the spellings s is searched by: s itself, s transliterated into the
+// other alphabet, and both without spaces, so that "Ди Каприо" matches "DiCaprio".
+// Duplicates are dropped.
+func Variants(s string) []string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+
+	var variants []string
+	add := func(v string) {
+		for _, seen := range variants {
+			if seen == v {
+				return
+			}
+		}
+		variants = append(variants, v)
+	}
+
+	for _, v := range []string{s, ToLatin(s), ToCyrillic(s)} {
+		add(v)
+		add(strings.ReplaceAll(v, " ", ""))
+	}
+	return variants
+}
@@ -0,0 +1,28 @@
+package translit_test
+
+import (
+	"testing"
+
+	"github.com/P1coFly/vk_movies/internal/translit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToLatin(t *testing.T) {
+	assert.Equal(t, "leonardo dikaprio", translit.ToLatin("Леонардо ДиКаприо"))
+	assert.Equal(t, "shchukin zhenya", translit.ToLatin("Щукин Женя"))
+	assert.Equal(t, "brad pitt", translit.ToLatin("Brad Pitt"))
+}
+
+func TestToCyrillic(t *testing.T) {
+	assert.Equal(t, "леонардо дикаприо", translit.ToCyrillic("Leonardo DiCaprio"))
+	assert.Equal(t, "щукин женя", translit.ToCyrillic("shchukin zhenya"))
+	assert.Equal(t, "мила кунис", translit.ToCyrillic("Mila Kunis"))
+}
+
+func TestVariants(t *testing.T) {
+	assert.Equal(t,
+		[]string{"ди каприо", "дикаприо", "di kaprio", "dikaprio"},
+		translit.Variants("  Ди   Каприо "),
+	)
+	assert.Equal(t, []string{"leonardo", "леонардо"}, translit.Variants("Leonardo"))
+}